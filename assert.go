@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// 响应断言树节点
+// and/or/not 为组合节点,path 不为空时为叶子条件,每个节点只能是其中一种
+type AssertNode struct {
+	And []AssertNode `json:"and,omitempty"`
+	Or  []AssertNode `json:"or,omitempty"`
+	Not *AssertNode  `json:"not,omitempty"`
+	// 叶子条件: gjson路径、比较操作符和期望值
+	Path  string `json:"path,omitempty"`
+	Op    string `json:"op,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// 叶子条件支持的比较操作符
+var assertOps = []string{"eq", "ne", "gt", "gte", "lt", "lte", "exists"}
+
+// 需要数值期望值的比较操作符
+var numericAssertOps = []string{"gt", "gte", "lt", "lte"}
+
+// 校验断言树的结构,在读取配置文件时调用
+func (n *AssertNode) Validate() error {
+	kinds := 0
+	if n.And != nil {
+		kinds++
+	}
+	if n.Or != nil {
+		kinds++
+	}
+	if n.Not != nil {
+		kinds++
+	}
+	if n.Path != "" {
+		kinds++
+	}
+	if kinds != 1 {
+		return errors.New(tr("assert_node_kind"))
+	}
+
+	switch {
+	case n.And != nil || n.Or != nil:
+		children := n.And
+		if n.Or != nil {
+			children = n.Or
+		}
+		if len(children) == 0 {
+			return errors.New(tr("assert_empty_children"))
+		}
+		for i := range children {
+			if err := children[i].Validate(); err != nil {
+				return err
+			}
+		}
+	case n.Not != nil:
+		return n.Not.Validate()
+	default:
+		if !slices.Contains(assertOps, n.Op) {
+			return fmt.Errorf(tr("assert_unknown_op"), n.Op, n.Path, assertOps)
+		}
+		if slices.Contains(numericAssertOps, n.Op) {
+			if _, ok := n.Value.(float64); !ok {
+				return fmt.Errorf(tr("assert_value_not_number"), n.Path, n.Op, n.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// 使用响应体计算断言树的结果
+func (n *AssertNode) Eval(body string) bool {
+	switch {
+	case n.And != nil:
+		for i := range n.And {
+			if !n.And[i].Eval(body) {
+				return false
+			}
+		}
+		return true
+	case n.Or != nil:
+		for i := range n.Or {
+			if n.Or[i].Eval(body) {
+				return true
+			}
+		}
+		return false
+	case n.Not != nil:
+		return !n.Not.Eval(body)
+	}
+
+	value := gjson.Get(body, n.Path)
+	switch n.Op {
+	case "exists":
+		return value.Exists()
+	case "eq":
+		return reflect.DeepEqual(value.Value(), n.Value)
+	case "ne":
+		return !reflect.DeepEqual(value.Value(), n.Value)
+	}
+	if value.Type != gjson.Number {
+		return false
+	}
+	expected := n.Value.(float64)
+	switch n.Op {
+	case "gt":
+		return value.Float() > expected
+	case "gte":
+		return value.Float() >= expected
+	case "lt":
+		return value.Float() < expected
+	case "lte":
+		return value.Float() <= expected
+	}
+	return false
+}
+
+// 断言树的可读表示,用于错误信息统计
+func (n *AssertNode) String() string {
+	join := func(nodes []AssertNode, sep string) string {
+		parts := make([]string, len(nodes))
+		for i := range nodes {
+			parts[i] = nodes[i].String()
+		}
+		return "(" + strings.Join(parts, sep) + ")"
+	}
+	switch {
+	case n.And != nil:
+		return join(n.And, " AND ")
+	case n.Or != nil:
+		return join(n.Or, " OR ")
+	case n.Not != nil:
+		return "NOT " + n.Not.String()
+	case n.Op == "exists":
+		return n.Path + " exists"
+	}
+	return fmt.Sprintf("%s %s %v", n.Path, n.Op, n.Value)
+}
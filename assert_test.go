@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// 断言树的结构校验: 每个节点只能是 and、or、not 或叶子条件之一,数值比较要求数值期望值
+func TestAssertNodeValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{"leaf", `{"path":"code","op":"eq","value":0}`, false},
+		{"exists", `{"path":"data.id","op":"exists"}`, false},
+		{"and", `{"and":[{"path":"a","op":"eq","value":1},{"path":"b","op":"ne","value":"x"}]}`, false},
+		{"nested", `{"or":[{"not":{"path":"a","op":"exists"}},{"and":[{"path":"b","op":"gte","value":1}]}]}`, false},
+		{"empty node", `{}`, true},
+		{"two kinds", `{"path":"a","op":"exists","not":{"path":"b","op":"exists"}}`, true},
+		{"and with or", `{"and":[{"path":"a","op":"exists"}],"or":[{"path":"b","op":"exists"}]}`, true},
+		{"empty and", `{"and":[]}`, true},
+		{"unknown op", `{"path":"a","op":"like","value":"x"}`, true},
+		{"missing op", `{"path":"a"}`, true},
+		{"numeric op with string", `{"path":"a","op":"gt","value":"1"}`, true},
+		{"invalid child", `{"or":[{"path":"a","op":"exists"},{"path":"b","op":"bad"}]}`, true},
+		{"invalid not", `{"not":{}}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node AssertNode
+			if err := json.Unmarshal([]byte(tt.json), &node); err != nil {
+				t.Fatal(err)
+			}
+			if err := node.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 断言树的计算: and 全部满足、or 任一满足、not 取反,数值比较只对数值字段生效
+func TestAssertNodeEval(t *testing.T) {
+	const body = `{"code":0,"msg":"ok","data":{"id":7,"tags":["a","b"],"price":9.5,"count":"3"},"empty":null}`
+	tests := []struct {
+		name string
+		json string
+		want bool
+	}{
+		{"eq number", `{"path":"code","op":"eq","value":0}`, true},
+		{"eq string", `{"path":"msg","op":"eq","value":"ok"}`, true},
+		{"eq type differs", `{"path":"data.count","op":"eq","value":3}`, false},
+		{"eq array", `{"path":"data.tags","op":"eq","value":["a","b"]}`, true},
+		{"eq null", `{"path":"empty","op":"eq","value":null}`, true},
+		{"ne", `{"path":"msg","op":"ne","value":"fail"}`, true},
+		{"exists", `{"path":"data.id","op":"exists"}`, true},
+		{"exists missing", `{"path":"data.name","op":"exists"}`, false},
+		{"gt", `{"path":"data.id","op":"gt","value":5}`, true},
+		{"gt equal", `{"path":"data.id","op":"gt","value":7}`, false},
+		{"gte equal", `{"path":"data.id","op":"gte","value":7}`, true},
+		{"lt", `{"path":"data.price","op":"lt","value":10}`, true},
+		{"lte", `{"path":"data.price","op":"lte","value":9}`, false},
+		{"numeric op on string", `{"path":"data.count","op":"gt","value":1}`, false},
+		{"numeric op on missing", `{"path":"data.name","op":"lt","value":1}`, false},
+		{"and all true", `{"and":[{"path":"code","op":"eq","value":0},{"path":"data.id","op":"exists"}]}`, true},
+		{"and one false", `{"and":[{"path":"code","op":"eq","value":0},{"path":"data.id","op":"lt","value":0}]}`, false},
+		{"or one true", `{"or":[{"path":"code","op":"eq","value":1},{"path":"msg","op":"eq","value":"ok"}]}`, true},
+		{"or all false", `{"or":[{"path":"code","op":"eq","value":1},{"path":"msg","op":"eq","value":"fail"}]}`, false},
+		{"not", `{"not":{"path":"data.name","op":"exists"}}`, true},
+		{"nested", `{"and":[{"or":[{"path":"code","op":"eq","value":1},{"not":{"path":"code","op":"ne","value":0}}]},{"path":"data.price","op":"gte","value":9.5}]}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node AssertNode
+			if err := json.Unmarshal([]byte(tt.json), &node); err != nil {
+				t.Fatal(err)
+			}
+			if err := node.Validate(); err != nil {
+				t.Fatalf("Validate(%s) = %v", tt.json, err)
+			}
+			if got := node.Eval(body); got != tt.want {
+				t.Errorf("Eval(%s) = %v, want %v", node.String(), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 发起连接使用的本地地址,通过 -bind 指定,多个地址时每个新连接轮流使用,为空时由系统选择
+var bindAddrs []net.IP
+
+// 下一个新连接使用的本地地址序号
+var bindNext atomic.Uint64
+
+// 解析 -bind,多个地址用逗号分隔,每项为IP地址或网卡名称,
+// 网卡名称使用该网卡的所有单播地址(不包括需要指定网卡的IPv6链路本地地址)
+func parseBindAddrs(s string) ([]net.IP, error) {
+	var addrs []net.IP
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if ip := net.ParseIP(part); ip != nil {
+			addrs = append(addrs, ip)
+			continue
+		}
+		iface, err := net.InterfaceByName(part)
+		if err != nil {
+			return nil, fmt.Errorf(tr("invalid_bind"), part)
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, addr := range ifaceAddrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			addrs = append(addrs, ipNet.IP)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf(tr("bind_no_addr"), part)
+		}
+	}
+	return addrs, nil
+}
+
+// 返回 base 的副本,配置了 -bind 时使用下一个本地地址发起连接到 addr
+// 目标为IP地址时只使用同一协议族(IPv4/IPv6)的本地地址,为域名时只会连接与本地地址同一协议族的解析结果
+func bindDialer(base net.Dialer, addr string) *net.Dialer {
+	if len(bindAddrs) == 0 {
+		return &base
+	}
+	var target net.IP
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		target = net.ParseIP(host)
+	}
+	start := bindNext.Add(1) - 1
+	for i := range uint64(len(bindAddrs)) {
+		ip := bindAddrs[(start+i)%uint64(len(bindAddrs))]
+		if target == nil || (ip.To4() == nil) == (target.To4() == nil) {
+			base.LocalAddr = &net.TCPAddr{IP: ip}
+			break
+		}
+	}
+	return &base
+}
+
+// 与 http.DefaultTransport 相同的连接超时和 keep-alive 间隔
+var defaultDialer = net.Dialer{
+	Timeout:   30 * time.Second,
+	KeepAlive: 30 * time.Second,
+}
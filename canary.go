@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// 告警中单个未通过的请求配置
+type canaryFailure struct {
+	URL     string   `json:"url"`
+	Method  string   `json:"method"`
+	Reasons []string `json:"reasons"`
+}
+
+// 发送到webhook的告警内容
+type canaryAlert struct {
+	Config   string          `json:"config"`
+	Time     string          `json:"time"`
+	Failures []canaryFailure `json:"failures"`
+}
+
+// 持续监测模式: 每隔 interval 运行一次测试,仅在结果未通过时输出告警并推送webhook,被中断时返回
+func runCanary(requestList []RequestConfig, concurrency, totalRequests, timeout int64, interval time.Duration, webhook string) {
+	quiet = true
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		results := runTest(requestList, concurrency, totalRequests, timeout)
+
+		alert := canaryAlert{
+			Config: configFileName,
+			Time:   time.Now().Format(time.RFC3339),
+		}
+		for _, reqResult := range results {
+			if reasons := checkResult(reqResult); len(reasons) > 0 {
+				alert.Failures = append(alert.Failures, canaryFailure{
+					URL:     reqResult.RequestConfig.URL,
+					Method:  reqResult.RequestConfig.Method,
+					Reasons: reasons,
+				})
+			}
+		}
+		if len(alert.Failures) > 0 {
+			for _, failure := range alert.Failures {
+				fmt.Printf(tr("canary_alert"), alert.Time, failure.Method, failure.URL, failure.Reasons)
+			}
+			if webhook != "" {
+				if err := postWebhook(webhook, alert, timeout); err != nil {
+					fmt.Printf(tr("webhook_failed"), webhook, err)
+				}
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-runCtx.Done():
+			return
+		}
+	}
+}
+
+// 以JSON格式POST数据到webhook
+func postWebhook(webhook string, payload any, timeout int64) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
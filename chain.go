@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tidwall/gjson"
+)
+
+// 从响应中提取的变量,变量名 -> 值,由 prepareChain 在压测前更新
+var chainVariables = map[string]string{}
+
+// 压测前依次为配置了 Extract 的请求配置发送一次请求并提取变量,后面的配置可以引用前面提取的变量
+// 返回使用提取到的变量替换 ${变量名} 引用后的请求配置
+func prepareChain(requestList []RequestConfig, timeout int64) []RequestConfig {
+	prepared := make([]RequestConfig, len(requestList))
+	for index, request := range requestList {
+		request = applyVariables(request)
+		if len(request.Extract) > 0 {
+			// 提取请求与压测请求使用相同的超时时间、代理和默认请求头设置
+			handler := newConfigHandler(request, timeout)
+			if err := extractVariables(handler, request); err != nil {
+				fmt.Printf(tr("extract_failed"), index+1, err)
+			}
+			handler.Close()
+		}
+		prepared[index] = request
+	}
+	return prepared
+}
+
+// 发送一次请求并按 Extract 提取变量
+func extractVariables(handler *RequestHandler, request RequestConfig) error {
+	reqConfig := request.nextRequest()
+	resp, _, err := handler.NewRequest(runCtx, reqConfig, nil)
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf(tr("read_body_error"), err)
+	}
+	if !reqConfig.Response.Status.matches(resp.StatusCode) {
+		return fmt.Errorf(tr("smoke_status_mismatch"), reqConfig.Response.Status, resp.StatusCode)
+	}
+	for path, name := range request.Extract {
+		value := gjson.GetBytes(body, path)
+		if !value.Exists() {
+			return fmt.Errorf(tr("extract_path_missing"), path)
+		}
+		chainVariables[name] = value.String()
+	}
+	return nil
+}
+
+// 使用已提取的变量替换请求配置中的 ${变量名} 引用,未提取到的变量保留原样
+func applyVariables(request RequestConfig) RequestConfig {
+	if len(chainVariables) == 0 {
+		return request
+	}
+	return expandConfig(request, func(name string) string {
+		if value, ok := chainVariables[name]; ok {
+			return value
+		}
+		return "${" + name + "}"
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 数据文件中覆盖该行请求期望状态码的列名
+const expectedStatusColumn = "expected_status"
+
+// CSV数据文件中的数据行,表头为变量名,每个请求按顺序使用下一行,用完后从第一行重新开始
+type dataRows struct {
+	columns []string
+	rows    []map[string]string
+	// 每行 expected_status 列解析后的期望状态码,没有该列或值为空时为 nil
+	statuses []expectedStatus
+	next     atomic.Uint64
+}
+
+// 读取CSV数据文件,第一行为表头,至少需要一行数据
+func loadDataFile(filePath string) (*dataRows, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, errors.New(tr("data_file_empty"))
+	}
+	data := &dataRows{}
+	for _, column := range records[0] {
+		data.columns = append(data.columns, strings.TrimSpace(column))
+	}
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(data.columns))
+		for i, column := range data.columns {
+			row[column] = record[i]
+		}
+		status, err := parseRowStatus(row[expectedStatusColumn])
+		if err != nil {
+			return nil, err
+		}
+		data.rows = append(data.rows, row)
+		data.statuses = append(data.statuses, status)
+	}
+	return data, nil
+}
+
+// 解析 expected_status 列的值,如 201、4xx,多个状态码用 | 分隔,如 200|204,为空时返回 nil
+func parseRowStatus(value string) (expectedStatus, error) {
+	var status expectedStatus
+	for _, part := range strings.Split(value, "|") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if !validStatus(part) {
+			return nil, fmt.Errorf(tr("data_status_invalid"), value)
+		}
+		status = append(status, part)
+	}
+	return status, nil
+}
+
+// 返回包含 names 和所有列名的集合,读取配置文件时这些引用不作为环境变量替换
+func (d *dataRows) keepNames(names map[string]bool) map[string]bool {
+	merged := make(map[string]bool, len(names)+len(d.columns))
+	for name := range names {
+		merged[name] = true
+	}
+	for _, column := range d.columns {
+		merged[column] = true
+	}
+	return merged
+}
+
+// 使用下一行数据替换请求配置中的 ${列名} 引用,该行有 expected_status 时覆盖期望状态码,
+// 多个协程并发调用时按顺序各取一行
+func (d *dataRows) apply(request RequestConfig) RequestConfig {
+	index := (d.next.Add(1) - 1) % uint64(len(d.rows))
+	row := d.rows[index]
+	request = expandConfig(request, func(name string) string {
+		if value, ok := row[name]; ok {
+			return value
+		}
+		return "${" + name + "}"
+	})
+	if status := d.statuses[index]; status != nil {
+		request.Response.Status = status
+	}
+	return request
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// 是否只输出每个配置解析后的第一个请求而不发送,通过 -dry-run 指定
+var dryRun bool
+
+// 输出请求体的最大字节数,超过时截断
+const dryRunBodyLimit = 4096
+
+// 输出每个请求配置解析后的第一个请求(方法、最终URL、请求头和请求体),不发送任何请求
+// 数据文件使用第一行数据,模板函数正常求值,请求链变量尚未提取,引用保留原样
+func runDryRun(requestList []RequestConfig, timeout int64) {
+	for index, request := range requestList {
+		fmt.Printf(tr("result_title"), index+1)
+		request = request.nextRequest()
+		switch request.Protocol {
+		case protocolGRPC, protocolWebSocket:
+			printDryRunMessage(request)
+			continue
+		}
+		handler := newConfigHandler(request, timeout)
+		defer handler.Close()
+		req, _, err := handler.BuildRequest(context.Background(), request, nil)
+		if err != nil {
+			fmt.Printf(tr("dry_run_failed"), err)
+			continue
+		}
+		fmt.Printf("%s %s %s\n", req.Method, req.URL, req.Proto)
+		printDryRunHeaders(req.Header)
+		// 有序请求头在发送时按配置的顺序和大小写写入
+		for _, header := range request.OrderedHeaders {
+			fmt.Printf("%s: %s\n", header[0], header[1])
+		}
+		if req.Body == nil {
+			continue
+		}
+		var body io.Reader = req.Body
+		// 压缩后的请求体输出压缩前的内容
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			if body, err = gzip.NewReader(req.Body); err != nil {
+				fmt.Printf(tr("dry_run_failed"), err)
+				continue
+			}
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			fmt.Printf(tr("dry_run_failed"), err)
+			continue
+		}
+		printDryRunBody(data)
+	}
+}
+
+// 输出gRPC调用或WebSocket消息,请求头在gRPC中作为元数据、在WebSocket中随握手请求发送
+func printDryRunMessage(request RequestConfig) {
+	if request.Protocol == protocolGRPC {
+		fmt.Printf("%s %s/%s\n", request.URL, request.Service, request.Method)
+	} else {
+		fmt.Println(request.URL)
+	}
+	header := make(http.Header)
+	if request.Auth != nil {
+		header.Set("Authorization", request.Auth.header())
+	}
+	for key, value := range request.Headers {
+		header.Set(key, value)
+	}
+	printDryRunHeaders(header)
+	if request.Data == nil {
+		return
+	}
+	data, err := encodeRequestBody(request.Data)
+	if err != nil {
+		fmt.Printf(tr("dry_run_failed"), err)
+		return
+	}
+	printDryRunBody(data)
+}
+
+// 按名称顺序输出请求头
+func printDryRunHeaders(header http.Header) {
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[key] {
+			// 空值的请求头不会被发送
+			if value != "" {
+				fmt.Printf("%s: %s\n", key, value)
+			}
+		}
+	}
+}
+
+// 输出请求体,超过 dryRunBodyLimit 时截断并输出总字节数
+func printDryRunBody(data []byte) {
+	fmt.Println()
+	if len(data) > dryRunBodyLimit {
+		fmt.Printf("%s\n", data[:dryRunBodyLimit])
+		fmt.Printf(tr("dry_run_truncated"), len(data))
+		return
+	}
+	fmt.Printf("%s\n", data)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// 状态码错误时记录的响应体前N字节,通过 -error-body 指定,0表示不记录
+var errorBodyBytes = 200
+
+// 每个配置最多记录的不同错误响应内容数,超过后新出现的内容不再记录,避免响应中包含请求ID等时占用过多内存
+const maxErrorBodies = 100
+
+// 状态码错误的响应内容及次数
+type ErrorBody struct {
+	Status int
+	Body   string
+	Count  int
+}
+
+// 按状态码和截断后的响应内容合并计数的key
+type errorBodyKey struct {
+	status int
+	body   string
+}
+
+// 截取响应体的前 errorBodyBytes 字节并将连续空白合并为一个空格,便于合并相同的错误内容
+func errorBodySnippet(body []byte) string {
+	if len(body) > errorBodyBytes {
+		body = body[:errorBodyBytes]
+	}
+	return strings.Join(strings.Fields(strings.ToValidUTF8(string(body), "")), " ")
+}
+
+// 记录一次状态码错误的响应内容
+func (r *Result) addErrorBody(status int, body []byte) {
+	if errorBodyBytes <= 0 {
+		return
+	}
+	r.addErrorBodyCount(errorBodyKey{status: status, body: errorBodySnippet(body)}, 1)
+}
+
+func (r *Result) addErrorBodyCount(key errorBodyKey, count int) {
+	if _, ok := r.errorBodies[key]; !ok && len(r.errorBodies) >= maxErrorBodies {
+		return
+	}
+	if r.errorBodies == nil {
+		r.errorBodies = make(map[errorBodyKey]int)
+	}
+	r.errorBodies[key] += count
+}
+
+// 按次数从高到低排序后的错误响应内容
+func (r *Result) sortedErrorBodies() []ErrorBody {
+	if len(r.errorBodies) == 0 {
+		return nil
+	}
+	bodies := make([]ErrorBody, 0, len(r.errorBodies))
+	for key, count := range r.errorBodies {
+		bodies = append(bodies, ErrorBody{Status: key.status, Body: key.body, Count: count})
+	}
+	slices.SortFunc(bodies, func(a, b ErrorBody) int {
+		return cmp.Or(b.Count-a.Count, a.Status-b.Status, strings.Compare(a.Body, b.Body))
+	})
+	return bodies
+}
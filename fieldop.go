@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// Response.Data 中结构断言的期望值前缀,如 exists:true、len:>=5、contains:admin
+const (
+	existsFieldPrefix   = "exists:"
+	lenFieldPrefix      = "len:"
+	containsFieldPrefix = "contains:"
+)
+
+// 长度比较运算符,按前缀匹配时较长的运算符在前
+var lenOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// 结构断言,只校验字段是否存在、长度或包含关系,不要求精确的值
+type fieldOperator struct {
+	// 原始期望值,用于错误信息统计
+	expr   string
+	prefix string
+	exists bool
+	op     string
+	length int
+	substr string
+}
+
+// 解析结构断言,期望值不是以上前缀开头的字符串时返回 false
+func parseFieldOperator(expected any) (fieldOperator, bool, error) {
+	expr, ok := expected.(string)
+	if !ok {
+		return fieldOperator{}, false, nil
+	}
+	o := fieldOperator{expr: expr}
+	switch {
+	case strings.HasPrefix(expr, existsFieldPrefix):
+		o.prefix = existsFieldPrefix
+		exists, err := strconv.ParseBool(strings.TrimPrefix(expr, existsFieldPrefix))
+		if err != nil {
+			return o, true, err
+		}
+		o.exists = exists
+	case strings.HasPrefix(expr, lenFieldPrefix):
+		o.prefix = lenFieldPrefix
+		spec := strings.TrimSpace(strings.TrimPrefix(expr, lenFieldPrefix))
+		o.op = "=="
+		for _, op := range lenOperators {
+			if strings.HasPrefix(spec, op) {
+				o.op = op
+				spec = strings.TrimSpace(strings.TrimPrefix(spec, op))
+				break
+			}
+		}
+		length, err := strconv.Atoi(spec)
+		if err != nil {
+			return o, true, err
+		}
+		o.length = length
+	case strings.HasPrefix(expr, containsFieldPrefix):
+		o.prefix = containsFieldPrefix
+		o.substr = strings.TrimPrefix(expr, containsFieldPrefix)
+	default:
+		return fieldOperator{}, false, nil
+	}
+	return o, true, nil
+}
+
+// 判断字段是否满足断言
+// len 对数组为元素个数、对对象为key个数、对字符串为字符数,其他类型不满足;
+// contains 对数组要求存在转换为字符串后相等的元素,对字符串要求包含子串,其他类型不满足
+func (o fieldOperator) Check(value gjson.Result) bool {
+	switch o.prefix {
+	case existsFieldPrefix:
+		return value.Exists() == o.exists
+	case lenFieldPrefix:
+		length, ok := fieldLength(value)
+		if !ok {
+			return false
+		}
+		switch o.op {
+		case ">=":
+			return length >= o.length
+		case "<=":
+			return length <= o.length
+		case "!=":
+			return length != o.length
+		case ">":
+			return length > o.length
+		case "<":
+			return length < o.length
+		}
+		return length == o.length
+	case containsFieldPrefix:
+		if value.IsArray() {
+			for _, item := range value.Array() {
+				if item.String() == o.substr {
+					return true
+				}
+			}
+			return false
+		}
+		return value.Type == gjson.String && strings.Contains(value.String(), o.substr)
+	}
+	return false
+}
+
+func (o fieldOperator) String() string {
+	return o.expr
+}
+
+// 获取数组、对象或字符串字段的长度
+func fieldLength(value gjson.Result) (int, bool) {
+	switch {
+	case value.IsArray():
+		return len(value.Array()), true
+	case value.IsObject():
+		return len(value.Map()), true
+	case value.Type == gjson.String:
+		return utf8.RuneCountInString(value.String()), true
+	}
+	return 0, false
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+// 结构断言的解析: 只识别 exists:、len:、contains: 前缀的字符串,其余期望值按精确值比较
+func TestParseFieldOperator(t *testing.T) {
+	tests := []struct {
+		expected   any
+		wantOK     bool
+		wantErr    bool
+		wantOp     string
+		wantLength int
+	}{
+		{"exists:true", true, false, "", 0},
+		{"exists:false", true, false, "", 0},
+		{"exists:maybe", true, true, "", 0},
+		{"len:5", true, false, "==", 5},
+		{"len:=5", true, false, "=", 5},
+		{"len:==5", true, false, "==", 5},
+		{"len:>=5", true, false, ">=", 5},
+		{"len:<= 5", true, false, "<=", 5},
+		{"len: != 0", true, false, "!=", 0},
+		{"len:>1", true, false, ">", 1},
+		{"len:<1", true, false, "<", 1},
+		{"len:>=", true, true, "", 0},
+		{"len:abc", true, true, "", 0},
+		{"contains:admin", true, false, "", 0},
+		{"contains:", true, false, "", 0},
+		{"admin", false, false, "", 0},
+		{"Exists:true", false, false, "", 0},
+		{5.0, false, false, "", 0},
+		{true, false, false, "", 0},
+		{nil, false, false, "", 0},
+	}
+	for _, tt := range tests {
+		o, ok, err := parseFieldOperator(tt.expected)
+		if ok != tt.wantOK || (err != nil) != tt.wantErr {
+			t.Errorf("parseFieldOperator(%#v) = ok %v, err %v; want ok %v, wantErr %v", tt.expected, ok, err, tt.wantOK, tt.wantErr)
+			continue
+		}
+		if tt.wantOK && !tt.wantErr && (o.op != tt.wantOp || o.length != tt.wantLength) {
+			t.Errorf("parseFieldOperator(%#v) = op %q length %d, want op %q length %d", tt.expected, o.op, o.length, tt.wantOp, tt.wantLength)
+		}
+	}
+}
+
+// 结构断言的判断: len 按数组元素、对象key或字符串字符计数,contains 对数组比较元素、对字符串查找子串
+func TestFieldOperatorCheck(t *testing.T) {
+	const body = `{"list":[1,"a",true],"obj":{"a":1,"b":2},"name":"张三丰","num":123,"empty":[],"null":null}`
+	tests := []struct {
+		expr string
+		path string
+		want bool
+	}{
+		{"exists:true", "name", true},
+		{"exists:true", "missing", false},
+		{"exists:false", "missing", true},
+		{"exists:false", "name", false},
+		{"exists:true", "null", true},
+		{"len:3", "list", true},
+		{"len:>=3", "list", true},
+		{"len:>3", "list", false},
+		{"len:<=2", "obj", true},
+		{"len:2", "obj", true},
+		{"len:3", "name", true},
+		{"len:!=0", "empty", false},
+		{"len:0", "empty", true},
+		{"len:<5", "num", false},
+		{"len:0", "missing", false},
+		{"contains:a", "list", true},
+		{"contains:1", "list", true},
+		{"contains:true", "list", true},
+		{"contains:b", "list", false},
+		{"contains:三", "name", true},
+		{"contains:李", "name", false},
+		{"contains:12", "num", false},
+		{"contains:a", "obj", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr+" "+tt.path, func(t *testing.T) {
+			o, ok, err := parseFieldOperator(tt.expr)
+			if !ok || err != nil {
+				t.Fatalf("parseFieldOperator(%q) = ok %v, err %v", tt.expr, ok, err)
+			}
+			if got := o.Check(gjson.Get(body, tt.path)); got != tt.want {
+				t.Errorf("%q.Check(%s) = %v, want %v", tt.expr, tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// gRPC请求使用的协议名称
+const protocolGRPC = "grpc"
+
+// 通过服务端反射获取的gRPC方法
+type grpcMethod struct {
+	conn *grpc.ClientConn
+	// 完整方法名,如 /helloworld.Greeter/SayHello
+	fullName string
+	input    protoreflect.MessageDescriptor
+	output   protoreflect.MessageDescriptor
+}
+
+// 每个请求处理器缓存的gRPC连接和方法,同一地址只建立一个连接
+type grpcClients struct {
+	mu      sync.Mutex
+	conns   map[string]*grpc.ClientConn
+	methods map[string]*grpcMethod
+}
+
+// 关闭所有gRPC连接并清空缓存的方法
+func (c *grpcClients) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+	c.conns = nil
+	c.methods = nil
+}
+
+// gRPC请求超时,实现 net.Error 以便与HTTP请求超时一起统计
+type grpcTimeoutError struct {
+	error
+}
+
+func (grpcTimeoutError) Timeout() bool   { return true }
+func (grpcTimeoutError) Temporary() bool { return false }
+
+// 解析gRPC地址,grpcs:// 使用TLS(-insecure、-cert、-key 同样生效),grpc:// 或不带协议时不加密
+func parseGRPCTarget(rawURL string) (target string, secure bool) {
+	if after, ok := strings.CutPrefix(rawURL, "grpcs://"); ok {
+		return after, true
+	}
+	return strings.TrimPrefix(rawURL, "grpc://"), false
+}
+
+// 获取gRPC方法,首次调用时建立连接并通过服务端反射获取方法的请求和响应类型
+func (h *RequestHandler) grpcMethod(config RequestConfig) (*grpcMethod, error) {
+	target, secure := parseGRPCTarget(config.URL)
+	key := target + "/" + config.Service + "/" + config.Method
+
+	h.grpc.mu.Lock()
+	defer h.grpc.mu.Unlock()
+	if method, ok := h.grpc.methods[key]; ok {
+		return method, nil
+	}
+	if h.grpc.conns == nil {
+		h.grpc.conns = make(map[string]*grpc.ClientConn)
+		h.grpc.methods = make(map[string]*grpcMethod)
+	}
+	conn := h.grpc.conns[config.URL]
+	if conn == nil {
+		creds := insecure.NewCredentials()
+		if secure {
+			creds = credentials.NewTLS(tlsConfig)
+		}
+		options := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+		if len(bindAddrs) > 0 {
+			options = append(options, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+				return bindDialer(net.Dialer{}, addr).DialContext(ctx, "tcp", addr)
+			}))
+		}
+		var err error
+		if conn, err = grpc.NewClient(target, options...); err != nil {
+			return nil, err
+		}
+		h.grpc.conns[config.URL] = conn
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.client.Timeout)
+	defer cancel()
+	service, err := resolveGRPCService(ctx, conn, config.Service)
+	if err != nil {
+		return nil, fmt.Errorf(tr("grpc_reflection_failed"), config.Service, err)
+	}
+	methodDesc := service.Methods().ByName(protoreflect.Name(config.Method))
+	if methodDesc == nil {
+		return nil, fmt.Errorf(tr("grpc_method_not_found"), config.Service, config.Method)
+	}
+	if methodDesc.IsStreamingClient() || methodDesc.IsStreamingServer() {
+		return nil, fmt.Errorf(tr("grpc_method_streaming"), config.Service, config.Method)
+	}
+	method := &grpcMethod{
+		conn:     conn,
+		fullName: "/" + string(service.FullName()) + "/" + config.Method,
+		input:    methodDesc.Input(),
+		output:   methodDesc.Output(),
+	}
+	h.grpc.methods[key] = method
+	return method, nil
+}
+
+// 通过服务端反射获取服务及其依赖的描述文件
+func resolveGRPCService(ctx context.Context, conn *grpc.ClientConn, serviceName string) (protoreflect.ServiceDescriptor, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	// 依次请求服务所在的文件和缺少的依赖文件
+	fileProtos := make(map[string]*descriptorpb.FileDescriptorProto)
+	request := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+	}
+	for request != nil {
+		if err := stream.Send(request); err != nil {
+			return nil, err
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if errResp := response.GetErrorResponse(); errResp != nil {
+			return nil, errors.New(errResp.GetErrorMessage())
+		}
+		for _, raw := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fileProto := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fileProto); err != nil {
+				return nil, err
+			}
+			fileProtos[fileProto.GetName()] = fileProto
+		}
+		request = nil
+		for _, fileProto := range fileProtos {
+			for _, dependency := range fileProto.GetDependency() {
+				if _, ok := fileProtos[dependency]; !ok {
+					request = &reflectionpb.ServerReflectionRequest{
+						MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+					}
+					break
+				}
+			}
+			if request != nil {
+				break
+			}
+		}
+	}
+
+	fileSet := &descriptorpb.FileDescriptorSet{}
+	for _, fileProto := range fileProtos {
+		fileSet.File = append(fileSet.File, fileProto)
+	}
+	files, err := protodesc.NewFiles(fileSet)
+	if err != nil {
+		return nil, err
+	}
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, err
+	}
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf(tr("grpc_not_service"), serviceName)
+	}
+	return service, nil
+}
+
+// 发送gRPC一元调用,将结果转换为HTTP响应以便使用相同的校验和统计逻辑
+// 调用成功时状态码为200,失败时为gRPC状态码(1-16),响应体为响应消息的JSON,gRPC状态写入 Grpc-Status、Grpc-Message 响应头
+func (h *RequestHandler) newGRPCRequest(ctx context.Context, config RequestConfig, timing *RequestTiming) (*http.Response, *http.Client, error) {
+	prepareStart := time.Now()
+	method, err := h.grpcMethod(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	input := dynamicpb.NewMessage(method.input)
+	if config.Data != nil {
+		data, err := encodeRequestBody(config.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := protojson.Unmarshal(data, input); err != nil {
+			return nil, nil, fmt.Errorf(tr("grpc_request_invalid"), err)
+		}
+	}
+	// 请求头和认证信息作为gRPC元数据发送
+	md := metadata.New(config.Headers)
+	if config.Auth != nil {
+		md.Set("authorization", config.Auth.header())
+	}
+	if md.Len() > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.client.Timeout)
+	defer cancel()
+	if timing != nil {
+		timing.Prepare = time.Since(prepareStart)
+	}
+
+	output := dynamicpb.NewMessage(method.output)
+	var header metadata.MD
+	err = method.conn.Invoke(ctx, method.fullName, input, output, grpc.Header(&header))
+	if timing != nil {
+		timing.FirstByte = time.Now()
+	}
+	// 超时和被取消的请求与HTTP请求一样返回错误
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, nil, grpcTimeoutError{err}
+		}
+		return nil, nil, ctx.Err()
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, nil, err
+	}
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/2.0",
+		ProtoMajor: 2,
+		Header:     make(http.Header),
+		Request:    &http.Request{Method: http.MethodPost, URL: &url.URL{Scheme: protocolGRPC, Host: method.conn.Target(), Path: method.fullName}, Header: http.Header(md)},
+	}
+	for key, values := range header {
+		for _, value := range values {
+			resp.Header.Add(key, value)
+		}
+	}
+	resp.Header.Set("Grpc-Status", strconv.Itoa(int(st.Code())))
+	var body []byte
+	if st.Code() == codes.OK {
+		if body, err = protojson.Marshal(output); err != nil {
+			return nil, nil, err
+		}
+		resp.Header.Set("Content-Type", "application/json")
+	} else {
+		resp.StatusCode = int(st.Code())
+		resp.Header.Set("Grpc-Message", st.Message())
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 按 -har-sample 比例抽样记录请求和响应,通过 -export-har 导出,为 nil 时不记录
+var harRecorder *harLog
+
+// 抽样记录请求和响应的比例(0-1)
+var harSample float64
+
+// HAR 1.2 格式的根对象
+type harFile struct {
+	Log *harLog `json:"log"`
+}
+
+type harLog struct {
+	mu      sync.Mutex
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	started         time.Time
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	// 请求体不是有效的UTF-8文本(如gzip压缩后的请求体)时为 base64,Text 为base64编码的内容
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// 各阶段耗时,单位毫秒,-1 表示未记录
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func newHARLog() *harLog {
+	return &harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "go-test", Version: "1.0"},
+		Entries: []harEntry{},
+	}
+}
+
+// 按抽样比例记录一次请求和响应,请求体为实际发送的字节(包括请求体文件、multipart 和压缩后的请求体)
+func (h *harLog) Record(resp *http.Response, body []byte, startTime time.Time, timing *RequestTiming) {
+	if h == nil || rand.Float64() >= harSample {
+		return
+	}
+	total := time.Since(startTime)
+	entry := harEntry{
+		started:         startTime,
+		StartedDateTime: startTime.Format(time.RFC3339Nano),
+		Time:            durationMs(total),
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Headers:     harHeaders(resp.Header),
+			Cookies:     []harNameValue{},
+			Content: harContent{
+				Size:     len(body),
+				MimeType: resp.Header.Get("Content-Type"),
+				Text:     string(body),
+			},
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Timings: harTimings{Send: -1, Wait: -1, Receive: -1},
+	}
+	if req := resp.Request; req != nil {
+		entry.Request = harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Headers:     harHeaders(req.Header),
+			QueryString: harHeaders(http.Header(req.URL.Query())),
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		entry.Request.PostData = harRequestBody(req)
+		if entry.Request.PostData != nil {
+			entry.Request.BodySize = int(req.ContentLength)
+		}
+	}
+	if timing != nil && !timing.FirstByte.IsZero() {
+		wait := timing.FirstByte.Sub(startTime)
+		entry.Timings = harTimings{Send: 0, Wait: durationMs(wait), Receive: durationMs(total - wait)}
+	}
+
+	h.mu.Lock()
+	h.Entries = append(h.Entries, entry)
+	h.mu.Unlock()
+}
+
+// 通过 GetBody 重新读取请求实际发送的请求体,没有请求体时返回 nil
+func harRequestBody(req *http.Request) *harPostData {
+	if req.GetBody == nil || req.ContentLength == 0 {
+		return nil
+	}
+	reader, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return nil
+	}
+	postData := &harPostData{MimeType: req.Header.Get("Content-Type"), Text: string(data)}
+	if !utf8.Valid(data) {
+		postData.Text = base64.StdEncoding.EncodeToString(data)
+		postData.Encoding = "base64"
+	}
+	return postData
+}
+
+// 按请求开始时间排序后写入HAR文件
+func (h *harLog) Save(filePath string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sort.SliceStable(h.Entries, func(i, j int) bool {
+		return h.Entries[i].started.Before(h.Entries[j].started)
+	})
+	data, err := json.MarshalIndent(harFile{Log: h}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filePath, data)
+}
+
+// 将请求头或查询参数转换为按名称排序的 name/value 列表
+func harHeaders(header http.Header) []harNameValue {
+	values := []harNameValue{}
+	for name, list := range header {
+		for _, value := range list {
+			values = append(values, harNameValue{Name: name, Value: value})
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].Name < values[j].Name
+	})
+	return values
+}
+
+// 时长转换为毫秒,保留小数
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// HAR 记录的请求体为实际发送的字节,包括请求体文件、multipart 和压缩后的请求体
+func TestHARRecordsSentBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+	defer func(sample float64) { harSample = sample }(harSample)
+	harSample = 1
+
+	tests := []struct {
+		name   string
+		config RequestConfig
+		check  func(t *testing.T, postData *harPostData)
+	}{
+		{"data", RequestConfig{Data: map[string]any{"a": 1.0}}, func(t *testing.T, postData *harPostData) {
+			if postData.Text != `{"a":1}` || postData.Encoding != "" {
+				t.Errorf("postData = %+v", postData)
+			}
+		}},
+		{"body file", RequestConfig{BodyFile: "body.txt", bodyFileData: []byte("from file")}, func(t *testing.T, postData *harPostData) {
+			if postData.Text != "from file" {
+				t.Errorf("postData = %+v", postData)
+			}
+		}},
+		{"multipart", RequestConfig{Form: map[string]string{"name": "value"}}, func(t *testing.T, postData *harPostData) {
+			if !strings.HasPrefix(postData.MimeType, "multipart/form-data") || !strings.Contains(postData.Text, "value") {
+				t.Errorf("postData = %+v", postData)
+			}
+		}},
+		{"compressed", RequestConfig{Data: "plain text", CompressRequest: true}, func(t *testing.T, postData *harPostData) {
+			if postData.Encoding != "base64" {
+				t.Fatalf("postData = %+v, want base64 encoding", postData)
+			}
+			data, err := base64.StdEncoding.DecodeString(postData.Text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reader, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain, _ := io.ReadAll(reader); string(plain) != "plain text" {
+				t.Errorf("decompressed body = %q", plain)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.URL = server.URL
+			tt.config.Method = "POST"
+			recorder := newHARLog()
+			handler := NewRequestHandler(time.Second)
+			start := time.Now()
+			resp, _, err := handler.NewRequest(context.Background(), tt.config, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			recorder.Record(resp, nil, start, nil)
+			if len(recorder.Entries) != 1 || recorder.Entries[0].Request.PostData == nil {
+				t.Fatalf("entries = %+v, want one entry with postData", recorder.Entries)
+			}
+			tt.check(t, recorder.Entries[0].Request.PostData)
+		})
+	}
+}
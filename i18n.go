@@ -0,0 +1,722 @@
+package main
+
+// 当前输出语言,通过 -lang 参数指定
+var lang = "zh"
+
+// 支持的输出语言
+var supportedLangs = []string{"zh", "en"}
+
+// 输出文案表: 文案key -> 语言 -> 文案
+var messages = map[string]map[string]string{
+	"read_config_failed": {
+		"zh": "读取配置文件%s失败: %v\n",
+		"en": "Failed to read config file %s: %v\n",
+	},
+	"load_env_file_failed": {
+		"zh": "加载环境变量文件%s失败: %v\n",
+		"en": "Failed to load env file %s: %v\n",
+	},
+	"load_cert_failed": {
+		"zh": "加载客户端证书失败: %v\n",
+		"en": "Failed to load client certificate: %v\n",
+	},
+	"metrics_server_failed": {
+		"zh": "启动指标服务%s失败: %v\n",
+		"en": "Failed to start metrics server %s: %v\n",
+	},
+	"load_shape_failed": {
+		"zh": "加载基准响应结构%s失败: %v\n",
+		"en": "Failed to load baseline shape %s: %v\n",
+	},
+	"no_request_config": {
+		"zh": "配置文件中未找到请求配置\n",
+		"en": "No request config found in config file\n",
+	},
+	"unsupported_lang": {
+		"zh": "不支持的语言: %s, 可选: %v\n",
+		"en": "Unsupported language: %s, available: %v\n",
+	},
+	"unsupported_output_format": {
+		"zh": "不支持的结果文件格式: %s, 可选: %v\n",
+		"en": "Unsupported output format: %s, available: %v\n",
+	},
+	"invalid_flags": {
+		"zh": "参数错误: -c(%d)、-n(%d)、-t(%d) 必须大于0\n",
+		"en": "Invalid flags: -c(%d), -n(%d) and -t(%d) must be greater than 0\n",
+	},
+	"write_file_failed": {
+		"zh": "写入文件%s失败: %v\n",
+		"en": "Failed to write file %s: %v\n",
+	},
+	"write_junit_failed": {
+		"zh": "写入JUnit报告%s失败: %v\n",
+		"en": "Failed to write JUnit report %s: %v\n",
+	},
+	"check_failed_requests": {
+		"zh": "存在失败请求: %d/%d",
+		"en": "Failed requests: %d/%d",
+	},
+	"invalid_interval": {
+		"zh": "参数错误: -interval(%v) 必须大于0\n",
+		"en": "Invalid flag: -interval(%v) must be greater than 0\n",
+	},
+	"canary_alert": {
+		"zh": "[%s] 告警: [%s] %s 未通过: %v\n",
+		"en": "[%s] ALERT: [%s] %s failed: %v\n",
+	},
+	"webhook_failed": {
+		"zh": "推送webhook %s 失败: %v\n",
+		"en": "Failed to post webhook %s: %v\n",
+	},
+	"invalid_client_chaos": {
+		"zh": "参数错误: -client-chaos(%v) 必须在0到1之间\n",
+		"en": "Invalid flag: -client-chaos(%v) must be between 0 and 1\n",
+	},
+	"invalid_har_sample": {
+		"zh": "参数错误: -har-sample(%v) 必须在0到1之间\n",
+		"en": "Invalid flag: -har-sample(%v) must be between 0 and 1\n",
+	},
+	"threshold_max_error_rate": {
+		"zh": "失败率 %.2f%% 超过最大允许值 %.2f%%",
+		"en": "error rate %.2f%% exceeds the maximum %.2f%%",
+	},
+	"threshold_max_p95": {
+		"zh": "p95耗时 %v 超过最大允许值 %v",
+		"en": "p95 latency %v exceeds the maximum %v",
+	},
+	"threshold_min_qps": {
+		"zh": "成功QPS %.2f 低于最低要求 %.2f",
+		"en": "successful QPS %.2f is below the minimum %.2f",
+	},
+	"threshold_violation": {
+		"zh": "未达标: 请求配置 #%d [%s] %s: %s\n",
+		"en": "Threshold violated: config #%d [%s] %s: %s\n",
+	},
+	"invalid_bind": {
+		"zh": "参数错误: -bind 中的 %q 不是IP地址或网卡名称",
+		"en": "Invalid flag: %q in -bind is not an IP address or interface name",
+	},
+	"bind_no_addr": {
+		"zh": "参数错误: -bind 中的网卡 %q 没有可用的地址",
+		"en": "Invalid flag: interface %q in -bind has no usable address",
+	},
+	"invalid_think": {
+		"zh": "参数错误: -think(%s) 必须是时长或时长范围,如 200ms、100-500ms",
+		"en": "Invalid flag: -think(%s) must be a duration or a duration range, e.g. 200ms or 100-500ms",
+	},
+	"invalid_step": {
+		"zh": "参数错误: -steps 中的并发数 %q 必须是正整数",
+		"en": "Invalid flag: concurrency %q in -steps must be a positive integer",
+	},
+	"step_start": {
+		"zh": "开始测试请求配置 #%d: [%s] %s, 并发数: %d, 运行时长: %v\n",
+		"en": "Start testing config #%d: [%s] %s, concurrency: %d, duration: %v\n",
+	},
+	"step_table_header": {
+		"zh": "并发数      All-QPS     OK-QPS      p95         成功率\n",
+		"en": "Concurrency All-QPS     OK-QPS      p95         Success\n",
+	},
+	"invalid_rampup": {
+		"zh": "参数错误: -rampup(%v) 不能小于0\n",
+		"en": "Invalid flag: -rampup(%v) must not be negative\n",
+	},
+	"invalid_bucket": {
+		"zh": "参数错误: -bucket(%d) 必须大于0\n",
+		"en": "Invalid flag: -bucket(%d) must be greater than 0\n",
+	},
+	"invalid_error_body": {
+		"zh": "参数错误: -error-body(%d) 不能小于0\n",
+		"en": "Invalid flag: -error-body(%d) must not be negative\n",
+	},
+	"invalid_repeat": {
+		"zh": "参数错误: -repeat(%d) 必须大于0\n",
+		"en": "Invalid flag: -repeat(%d) must be greater than 0\n",
+	},
+	"invalid_slowest": {
+		"zh": "参数错误: -slowest(%d) 不能小于0\n",
+		"en": "Invalid flag: -slowest(%d) must not be negative\n",
+	},
+	"invalid_warmup": {
+		"zh": "参数错误: -warmup(%d) 不能小于0\n",
+		"en": "Invalid flag: -warmup(%d) must not be negative\n",
+	},
+	"invalid_thresholds": {
+		"zh": "参数错误: -max-error-rate(%v) 不能大于100, -max-p95(%v) 不能小于0\n",
+		"en": "Invalid flag: -max-error-rate(%v) must not exceed 100 and -max-p95(%v) must not be negative\n",
+	},
+	"invalid_proxy": {
+		"zh": "参数错误: -proxy %v\n",
+		"en": "Invalid flag: -proxy %v\n",
+	},
+	"invalid_idle_conns": {
+		"zh": "参数错误: -max-idle-conns(%d) 和 -max-idle-conns-per-host(%d) 不能小于0\n",
+		"en": "Invalid flag: -max-idle-conns(%d) and -max-idle-conns-per-host(%d) must not be negative\n",
+	},
+	"invalid_mixed": {
+		"zh": "参数错误: -mixed 不能与 -parallel-configs 同时使用\n",
+		"en": "Invalid flag: -mixed cannot be used together with -parallel-configs\n",
+	},
+	"invalid_rate": {
+		"zh": "参数错误: -rate(%d) 不能小于0\n",
+		"en": "Invalid flag: -rate(%d) must not be negative\n",
+	},
+	"invalid_duration": {
+		"zh": "参数错误: -duration(%v) 不能小于0\n",
+		"en": "Invalid flag: -duration(%v) must not be negative\n",
+	},
+	"invalid_step_duration": {
+		"zh": "参数错误: -step-duration(%v) 必须大于0\n",
+		"en": "Invalid flag: -step-duration(%v) must be greater than 0\n",
+	},
+	"invalid_smoke_retries": {
+		"zh": "参数错误: -smoke-retries(%d) 不能小于0\n",
+		"en": "Invalid flag: -smoke-retries(%d) must not be negative\n",
+	},
+	"smoke_retry": {
+		"zh": "冒烟检查失败, 第%d/%d次重试: %v\n",
+		"en": "Smoke check failed, retry %d/%d: %v\n",
+	},
+	"smoke_failed": {
+		"zh": "冒烟检查失败, 跳过该配置: %v\n",
+		"en": "Smoke check failed, config skipped: %v\n",
+	},
+	"template_invalid": {
+		"zh": "请求配置 #%d 的模板函数无效: %v",
+		"en": "Invalid template function in config #%d: %v",
+	},
+	"template_args_invalid": {
+		"zh": "模板函数 %s 需要 %d 个整数参数",
+		"en": "template function %s takes %d integer arguments",
+	},
+	"template_range_invalid": {
+		"zh": "模板函数 %s 的最小值不能大于最大值",
+		"en": "template function %s has min greater than max",
+	},
+	"template_range_overflow": {
+		"zh": "模板函数 %s 的范围过大,最大值与最小值之差必须小于 9223372036854775807",
+		"en": "template function %s has a range too wide, max minus min must be less than 9223372036854775807",
+	},
+	"status_invalid": {
+		"zh": "期望状态码 %s 无效,需要为100-599的状态码或 2xx 形式的范围,或它们组成的数组",
+		"en": "invalid expected status %s, expected a code between 100 and 599, a range like 2xx, or an array of them",
+	},
+	"smoke_status_mismatch": {
+		"zh": "状态码错误, 期望: %s, 实际: %d",
+		"en": "unexpected status, expected: %s, actual: %d",
+	},
+	"check_smoke_failed": {
+		"zh": "冒烟检查失败: %s",
+		"en": "Smoke check failed: %s",
+	},
+	"interrupted": {
+		"zh": "\n收到中断信号, 停止发送新请求, 输出已完成请求的结果\n",
+		"en": "\nInterrupted, no new requests will be sent, reporting results collected so far\n",
+	},
+	"teardown_start": {
+		"zh": "执行清理配置 #%d: [%s] %s\n",
+		"en": "Running teardown config #%d: [%s] %s\n",
+	},
+	"teardown_failed": {
+		"zh": "清理配置 [%s] %s 执行失败: %v\n",
+		"en": "Teardown config [%s] %s failed: %v\n",
+	},
+	"extract_failed": {
+		"zh": "请求配置 #%d 提取变量失败: %v\n",
+		"en": "Failed to extract variables from config #%d: %v\n",
+	},
+	"extract_path_missing": {
+		"zh": "响应中不存在字段 %s",
+		"en": "field %s not found in response",
+	},
+	"start_test": {
+		"zh": "开始测试请求配置 #%d: [%s] %s\n",
+		"en": "Start testing config #%d: [%s] %s\n",
+	},
+	"read_body_error": {
+		"zh": "读取响应体错误: %v",
+		"en": "Failed to read response body: %v",
+	},
+	"response_body": {
+		"zh": "\n响应体内容: %s\n",
+		"en": "\nResponse body: %s\n",
+	},
+	"field_mismatch": {
+		"zh": "字段 %v 验证错误, 期望: %v, 实际: %v",
+		"en": "Field %v mismatch, expected: %v, actual: %v",
+	},
+	"field_out_of_range": {
+		"zh": "字段 %v 超出范围, 期望: %v, 实际: %v",
+		"en": "Field %v out of range, expected: %v, actual: %v",
+	},
+	"body_mismatch": {
+		"zh": "响应体验证错误, 期望: %q, 实际: %q",
+		"en": "Body mismatch, expected: %q, actual: %q",
+	},
+	"header_mismatch": {
+		"zh": "响应头 %v 验证错误, 期望: %v, 实际: %v",
+		"en": "Header %v mismatch, expected: %v, actual: %v",
+	},
+	"field_operator_mismatch": {
+		"zh": "字段 %v 不满足 %v, 实际: %v",
+		"en": "Field %v does not satisfy %v, actual: %v",
+	},
+	"field_regex_mismatch": {
+		"zh": "字段 %v 不匹配正则表达式 %v, 实际: %v",
+		"en": "Field %v does not match regex %v, actual: %v",
+	},
+	"field_type_mismatch": {
+		"zh": "字段 %v 类型错误, 期望: %v, 实际: %v",
+		"en": "Field %v type mismatch, expected: %v, actual: %v",
+	},
+	"shape_mismatch": {
+		"zh": "响应结构变化, 新增: %v, 缺失: %v",
+		"en": "Response shape changed, added: %v, removed: %v",
+	},
+	"cookie_missing": {
+		"zh": "Cookie %s 不存在",
+		"en": "Cookie %s is missing",
+	},
+	"cookie_attr_mismatch": {
+		"zh": "Cookie %s 的 %s 属性错误, 期望: %v, 实际: %v",
+		"en": "Cookie %s attribute %s mismatch, expected: %v, actual: %v",
+	},
+	"monotonic_not_number": {
+		"zh": "单调字段 %v 不是数字, 实际: %v",
+		"en": "Monotonic field %v is not a number, actual: %v",
+	},
+	"monotonic_decreased": {
+		"zh": "单调字段 %v 出现递减",
+		"en": "Monotonic field %v decreased",
+	},
+	"idempotency_replay_error": {
+		"zh": "幂等重复请求失败: %v",
+		"en": "Idempotent replay failed: %v",
+	},
+	"idempotency_status_mismatch": {
+		"zh": "幂等重复请求状态码不一致, 首次: %d, 重复: %d",
+		"en": "Idempotent replay status mismatch, first: %d, replay: %d",
+	},
+	"idempotency_body_mismatch": {
+		"zh": "幂等重复请求响应体不一致",
+		"en": "Idempotent replay body mismatch",
+	},
+	"url_parse_error": {
+		"zh": "URL解析错误: %v",
+		"en": "Failed to parse URL: %v",
+	},
+	"create_request_error": {
+		"zh": "创建请求失败: %v",
+		"en": "Failed to create request: %v",
+	},
+	"marshal_data_error": {
+		"zh": "数据序列化错误: %v",
+		"en": "Failed to marshal data: %v",
+	},
+	"compress_body_error": {
+		"zh": "请求体压缩错误: %v",
+		"en": "Failed to compress request body: %v",
+	},
+	"file_not_exist": {
+		"zh": "文件不存在: %v",
+		"en": "File does not exist: %v",
+	},
+	"body_file_invalid": {
+		"zh": "请求配置 #%d 的请求体文件错误: %v",
+		"en": "Invalid body file in config #%d: %v",
+	},
+	"env_expand_failed": {
+		"zh": "请求配置 #%d 的环境变量替换失败: %v",
+		"en": "Failed to expand environment variables in config #%d: %v",
+	},
+	"env_not_set": {
+		"zh": "环境变量未设置: %v",
+		"en": "environment variables not set: %v",
+	},
+	"field_operator_invalid": {
+		"zh": "请求配置 #%d 的字段 %s 断言 %q 错误: %v",
+		"en": "Invalid assertion %[3]q for field %[2]s in config #%[1]d: %[4]v",
+	},
+	"field_regex_invalid": {
+		"zh": "请求配置 #%d 的字段 %s 正则表达式错误: %v",
+		"en": "Invalid regex for field %[2]s in config #%[1]d: %[3]v",
+	},
+	"duration_invalid": {
+		"zh": "无效的时长 %s,应为秒数或时长字符串,如 \"500ms\"",
+		"en": "invalid duration %s, expected seconds or a duration string such as \"500ms\"",
+	},
+	"data_file_invalid": {
+		"zh": "请求配置 #%d 的数据文件错误: %v",
+		"en": "Invalid data file in config #%d: %v",
+	},
+	"data_status_invalid": {
+		"zh": "expected_status 列的值 %q 不是有效的状态码,如 201、4xx,多个状态码用 | 分隔",
+		"en": "expected_status value %q is not a valid status, e.g. 201 or 4xx, separate multiple statuses with |",
+	},
+	"data_file_empty": {
+		"zh": "数据文件至少需要表头和一行数据",
+		"en": "data file needs a header row and at least one data row",
+	},
+	"params_mode_invalid": {
+		"zh": "请求配置 #%d 的 paramsMode %q 不支持,可选 append、replace",
+		"en": "Unsupported paramsMode %[2]q in config #%[1]d, expected append or replace",
+	},
+	"match_invalid": {
+		"zh": "请求配置 #%d 的 response.match %q 不支持,可选 all、any",
+		"en": "Unsupported response.match %[2]q in config #%[1]d, expected all or any",
+	},
+	"config_dir_empty": {
+		"zh": "目录 %s 中没有 .json/.yaml/.yml 配置文件",
+		"en": "No .json/.yaml/.yml config files in directory %s",
+	},
+	"config_dir_file_invalid": {
+		"zh": "配置文件 %s 解析失败: %w",
+		"en": "Failed to parse config file %s: %w",
+	},
+	"method_invalid": {
+		"zh": "请求配置 #%d 的请求方法 %v 不支持,可选 GET、HEAD、POST、PUT、PATCH、DELETE、CONNECT、OPTIONS、TRACE",
+		"en": "Unsupported HTTP method %[2]v in config #%[1]d, expected GET, HEAD, POST, PUT, PATCH, DELETE, CONNECT, OPTIONS or TRACE",
+	},
+	"protocol_invalid": {
+		"zh": "请求配置 #%d 的协议 %q 不支持,可选 http、grpc、ws",
+		"en": "Unsupported protocol %[2]q in config #%[1]d, expected http, grpc or ws",
+	},
+	"ws_url_invalid": {
+		"zh": "请求配置 #%d 使用 ws 协议时地址需要以 ws:// 或 wss:// 开头: %s",
+		"en": "Config #%d uses the ws protocol and needs a ws:// or wss:// URL: %s",
+	},
+	"ws_closed": {
+		"zh": "WebSocket连接已被服务端关闭: %w",
+		"en": "WebSocket connection closed by server: %w",
+	},
+	"grpc_config_invalid": {
+		"zh": "请求配置 #%d 使用 grpc 协议时需要配置 service 和 method",
+		"en": "Config #%d uses the grpc protocol and needs both service and method",
+	},
+	"grpc_reflection_failed": {
+		"zh": "通过服务端反射获取服务 %s 失败: %v",
+		"en": "failed to resolve service %s via server reflection: %v",
+	},
+	"grpc_not_service": {
+		"zh": "%s 不是gRPC服务",
+		"en": "%s is not a gRPC service",
+	},
+	"grpc_method_not_found": {
+		"zh": "服务 %s 中不存在方法 %s",
+		"en": "service %s has no method %s",
+	},
+	"grpc_method_streaming": {
+		"zh": "%s/%s 是流式方法,只支持一元方法",
+		"en": "%s/%s is a streaming method, only unary methods are supported",
+	},
+	"grpc_request_invalid": {
+		"zh": "请求数据无法转换为gRPC请求消息: %v",
+		"en": "cannot convert data to the gRPC request message: %v",
+	},
+	"proxy_connect_failed": {
+		"zh": "代理拒绝建立隧道: %s",
+		"en": "proxy refused to open a tunnel: %s",
+	},
+	"proxy_invalid": {
+		"zh": "请求配置 #%d 的代理地址错误: %v",
+		"en": "Invalid proxy in config #%d: %v",
+	},
+	"proxy_scheme_invalid": {
+		"zh": "不支持的代理地址 %s,只支持 http、https、socks5",
+		"en": "unsupported proxy %s, only http, https and socks5 are supported",
+	},
+	"auth_invalid": {
+		"zh": "请求配置 #%d 的认证配置错误: %v",
+		"en": "Invalid auth in config #%d: %v",
+	},
+	"auth_type_invalid": {
+		"zh": "不支持的认证类型 %q,可选 basic、bearer",
+		"en": "unsupported auth type %q, expected basic or bearer",
+	},
+	"weight_invalid": {
+		"zh": "请求配置 #%d 的权重不能为负数: %d",
+		"en": "Weight in config #%d must not be negative: %d",
+	},
+	"timeout_invalid": {
+		"zh": "请求配置 #%d 的超时时间不能为负数: %v",
+		"en": "Timeout in config #%d must not be negative: %v",
+	},
+	"schema_invalid": {
+		"zh": "请求配置 #%d 的JSON Schema错误: %v",
+		"en": "Invalid JSON Schema in config #%d: %v",
+	},
+	"schema_failed": {
+		"zh": "响应不符合JSON Schema: %v",
+		"en": "Response does not match JSON Schema: %v",
+	},
+	"assert_invalid": {
+		"zh": "请求配置 #%d 的断言配置错误: %v",
+		"en": "Invalid assert in config #%d: %v",
+	},
+	"assert_node_kind": {
+		"zh": "断言节点必须且只能配置 and、or、not、path 其中之一",
+		"en": "an assert node must set exactly one of and, or, not, path",
+	},
+	"assert_empty_children": {
+		"zh": "and/or 断言节点不能为空",
+		"en": "and/or assert nodes must not be empty",
+	},
+	"assert_unknown_op": {
+		"zh": "未知的断言操作符 %q (路径 %s), 可选: %v",
+		"en": "unknown assert op %q (path %s), available: %v",
+	},
+	"assert_value_not_number": {
+		"zh": "断言 %s %s 的期望值必须是数字, 实际: %v",
+		"en": "assert %s %s requires a numeric value, got: %v",
+	},
+	"assert_failed": {
+		"zh": "断言失败: %v",
+		"en": "Assertion failed: %v",
+	},
+	"html_title": {
+		"zh": "压测报告",
+		"en": "Load Test Report",
+	},
+	"html_summary": {
+		"zh": "汇总",
+		"en": "Summary",
+	},
+	"html_distribution": {
+		"zh": "耗时分布",
+		"en": "Latency distribution",
+	},
+	"html_ttfb": {
+		"zh": "首字节耗时",
+		"en": "Time to first byte",
+	},
+	"html_col_total": {
+		"zh": "总请求",
+		"en": "Total",
+	},
+	"html_col_success": {
+		"zh": "成功数",
+		"en": "Success",
+	},
+	"html_col_timeout": {
+		"zh": "超时",
+		"en": "Timeouts",
+	},
+	"html_col_avg": {
+		"zh": "平均耗时",
+		"en": "Average",
+	},
+	"html_col_max": {
+		"zh": "最大耗时",
+		"en": "Max",
+	},
+	"result_debug": {
+		"zh": "请求结果: %#v \n",
+		"en": "Result: %#v \n",
+	},
+	"live_report": {
+		"zh": "[实时] QPS: %.2f, 成功率: %.2f%%, p95: %v, 已完成: %d\n",
+		"en": "[live] QPS: %.2f, success rate: %.2f%%, p95: %v, completed: %d\n",
+	},
+	"overall_title": {
+		"zh": "====== 汇总 ======\n",
+		"en": "====== Overall ======\n",
+	},
+	"overall_summary": {
+		"zh": "配置数: %d, 总请求: %d, 成功数: %d, 成功率: %.2f%%, 总耗时: %v\n",
+		"en": "Configs: %d, total: %d, success: %d, success rate: %.2f%%, elapsed: %v\n",
+	},
+	"dry_run_failed": {
+		"zh": "构建请求失败: %v\n",
+		"en": "Failed to build request: %v\n",
+	},
+	"dry_run_truncated": {
+		"zh": "...(请求体共 %d 字节,已截断)\n",
+		"en": "...(body is %d bytes, truncated)\n",
+	},
+	"repeat_run": {
+		"zh": "====== 第 %d/%d 次运行 ======\n",
+		"en": "====== Run %d/%d ======\n",
+	},
+	"repeat_title": {
+		"zh": "\n====== 多次运行对比 ======\n",
+		"en": "\n====== Run comparison ======\n",
+	},
+	"repeat_row": {
+		"zh": "第 %d 次: QPS: %.2f, p95: %v, 成功率: %.2f%%\n",
+		"en": "Run %d: QPS: %.2f, p95: %v, success rate: %.2f%%\n",
+	},
+	"repeat_qps": {
+		"zh": "QPS: 最小: %.2f, 最大: %.2f, 平均: %.2f\n",
+		"en": "QPS: min: %.2f, max: %.2f, mean: %.2f\n",
+	},
+	"repeat_p95": {
+		"zh": "p95: 最小: %v, 最大: %v, 平均: %v\n\n",
+		"en": "p95: min: %v, max: %v, mean: %v\n\n",
+	},
+	"result_config_file": {
+		"zh": "【配置文件】:%s\n",
+		"en": "【Config file】:%s\n",
+	},
+	"result_title": {
+		"zh": "====== 请求配置 #%d ======\n",
+		"en": "====== Config #%d ======\n",
+	},
+	"result_summary": {
+		"zh": "总请求: %d, 成功数: %d, 失败数: %d, 其中超时 %d, 成功率: %.2f%%\n",
+		"en": "Total: %d, success: %d, failed: %d, timeouts %d, success rate: %.2f%%\n",
+	},
+	"failure_breakdown": {
+		"zh": "失败分类: 状态码错误 %d (4xx %d, 5xx %d), 响应校验失败 %d, 超时 %d, 连接失败 %d, 网络错误 %d, 客户端中止 %d\n",
+		"en": "Failures: status code %d (4xx %d, 5xx %d), validation %d, timeout %d, connect %d, network %d, client aborted %d\n",
+	},
+	"result_time": {
+		"zh": "总耗时: %v, 最大耗时: %v, 最小耗时: %v, 平均耗时: %v, 标准差: %v \n",
+		"en": "Total time: %v, max: %v, min: %v, average: %v, std dev: %v \n",
+	},
+	"result_percentiles": {
+		"zh": "耗时百分位数: p50 %v, p90 %v, p95 %v, p99 %v \n",
+		"en": "Latency percentiles: p50 %v, p90 %v, p95 %v, p99 %v \n",
+	},
+	"zero_latency_warning": {
+		"zh": "警告: %.2f%% 的请求耗时为0ms,毫秒计时精度不足,平均耗时和耗时分布可能偏低\n",
+		"en": "Warning: %.2f%% of requests took 0ms, millisecond timer resolution is insufficient and average/distribution may be skewed low\n",
+	},
+	"response_size": {
+		"zh": "响应大小: 平均 %d 字节, 总计 %d 字节, 吞吐量: %.2f MB/s\n",
+		"en": "Response size: average %d bytes, total %d bytes, throughput: %.2f MB/s\n",
+	},
+	"request_body_size": {
+		"zh": "请求体平均大小: 压缩前 %d 字节, 压缩后 %d 字节\n",
+		"en": "Average request body size: %d bytes uncompressed, %d bytes compressed\n",
+	},
+	"method_results": {
+		"zh": "按请求方法统计:\n",
+		"en": "Results by method:\n",
+	},
+	"method_result": {
+		"zh": "[%s] 总请求: %d, 成功数: %d\n",
+		"en": "[%s] total: %d, success: %d\n",
+	},
+	"priority_delayed": {
+		"zh": "因优先级被推迟的请求数: %d (%.2f%%)\n",
+		"en": "Requests delayed by priority: %d (%.2f%%)\n",
+	},
+	"retry_count": {
+		"zh": "重试次数: %d\n",
+		"en": "Retries: %d\n",
+	},
+	"max_in_flight": {
+		"zh": "在途请求峰值: %d\n",
+		"en": "Max in-flight requests: %d\n",
+	},
+	"client_overhead": {
+		"zh": "客户端开销(准备和构建请求平均耗时,含数据行替换和模板求值): %dµs\n",
+		"en": "Client overhead (average request preparation and build time, including data rows and templates): %dµs\n",
+	},
+	"ws_handshake": {
+		"zh": "WebSocket握手平均耗时: %dµs (%d次),不计入消息往返耗时\n",
+		"en": "WebSocket handshake average: %dµs (%d times), excluded from message round-trip time\n",
+	},
+	"conn_phases": {
+		"zh": "建立连接平均耗时: DNS解析 %dµs (%d次), TCP连接 %dµs (%d次), TLS握手 %dµs (%d次)\n",
+		"en": "Connection setup average: DNS %dµs (%d times), TCP connect %dµs (%d times), TLS handshake %dµs (%d times)\n",
+	},
+	"ttfb_time": {
+		"zh": "首字节耗时: 平均 %v, p50 %v, p95 %v, p99 %v, 最大 %v; 响应传输平均耗时: %v\n",
+		"en": "Time to first byte: average %v, p50 %v, p95 %v, p99 %v, max %v; average transfer time: %v\n",
+	},
+	"idempotency_violations": {
+		"zh": "幂等性不一致次数: %d\n",
+		"en": "Idempotency violations: %d\n",
+	},
+	"monotonic_violations": {
+		"zh": "单调字段 %s 递减次数: %d\n",
+		"en": "Monotonic field %s decreased %d times\n",
+	},
+	"error_codes": {
+		"zh": "错误状态码:\n",
+		"en": "Error status codes:\n",
+	},
+	"error_bodies": {
+		"zh": "错误响应内容:\n",
+		"en": "Error response bodies:\n",
+	},
+	"error_body_row": {
+		"zh": "[%d次] 状态码 %d: %s\n",
+		"en": "[%d times] status %d: %s\n",
+	},
+	"error_categories": {
+		"zh": "网络错误分类:\n",
+		"en": "Network error categories:\n",
+	},
+	"error_category_dns": {
+		"zh": "DNS解析失败",
+		"en": "DNS lookup failed",
+	},
+	"error_category_refused": {
+		"zh": "连接被拒绝",
+		"en": "connection refused",
+	},
+	"error_category_tls": {
+		"zh": "TLS错误",
+		"en": "TLS error",
+	},
+	"error_category_reset": {
+		"zh": "连接被重置或提前关闭",
+		"en": "connection reset or closed early",
+	},
+	"error_category_other": {
+		"zh": "其他",
+		"en": "other",
+	},
+	"error_messages": {
+		"zh": "错误信息统计:\n",
+		"en": "Error messages:\n",
+	},
+	"slowest_title": {
+		"zh": "最慢的 %d 个请求:\n",
+		"en": "Slowest %d requests:\n",
+	},
+	"slowest_row": {
+		"zh": "  %v [%s] %s 状态码 %d\n",
+		"en": "  %v [%s] %s status %d\n",
+	},
+	"slowest_timeout": {
+		"zh": "  %v [%s] %s 超时\n",
+		"en": "  %v [%s] %s timed out\n",
+	},
+	"count_prefix": {
+		"zh": "[%d次] %v\n",
+		"en": "[%d times] %v\n",
+	},
+	"timeseries_title": {
+		"zh": "每秒统计:\n",
+		"en": "Per-second stats:\n",
+	},
+	"timeseries_row": {
+		"zh": "第%d秒: 请求数 %d, p50 %s, p95 %s, 最大 %s\n",
+		"en": "second %d: requests %d, p50 %s, p95 %s, max %s\n",
+	},
+	"distribution_title": {
+		"zh": "每%dms耗时统计次数:\n",
+		"en": "Requests per %dms latency bucket:\n",
+	},
+	"distribution_last": {
+		"zh": "%s+: %d次\n",
+		"en": "%s+: %d times\n",
+	},
+	"distribution_range": {
+		"zh": "%s-%s: %d次\n",
+		"en": "%s-%s: %d times\n",
+	},
+}
+
+// tr 返回当前语言下的文案,缺少翻译时回退到中文
+func tr(key string) string {
+	if texts, ok := messages[key]; ok {
+		if text, ok := texts[lang]; ok {
+			return text
+		}
+		return texts["zh"]
+	}
+	return key
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// 将测试结果写入JUnit XML报告,每个请求配置对应一个testcase
+func writeJUnit(filePath string, results []Result) error {
+	suite := junitTestSuite{
+		Name:  configFileName,
+		Tests: len(results),
+	}
+	var totalTime int64
+	for index, reqResult := range results {
+		totalTime += reqResult.TotalTime
+		testCase := junitTestCase{
+			Name:      fmt.Sprintf("#%d [%s] %s", index+1, reqResult.RequestConfig.Method, reqResult.RequestConfig.URL),
+			ClassName: configFileName,
+			Time:      fmt.Sprintf("%.3f", float64(reqResult.TotalTime)/1000),
+		}
+		if failures := checkResult(reqResult); len(failures) > 0 {
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: failures[0],
+				Content: strings.Join(failures, "\n"),
+			}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Time = fmt.Sprintf("%.3f", float64(totalTime)/1000)
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filePath, append([]byte(xml.Header), data...))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// 是否在测试过程中定时输出实时的QPS、成功率和p95,通过 -live 指定
+var liveReport bool
+
+// 实时输出的间隔
+const liveInterval = time.Second
+
+// 所有请求配置合计的指标快照
+type metricsSnapshot struct {
+	requests int64
+	success  int64
+	buckets  []int64
+}
+
+// 读取所有请求配置的指标并合计
+func (c *metricsCollector) snapshot() metricsSnapshot {
+	c.mu.Lock()
+	configs := append([]*configMetrics(nil), c.configs...)
+	c.mu.Unlock()
+
+	snapshot := metricsSnapshot{buckets: make([]int64, len(metricsBuckets)+1)}
+	for _, m := range configs {
+		snapshot.requests += m.requests.Load()
+		snapshot.success += m.success.Load()
+		for i := range m.buckets {
+			snapshot.buckets[i] += m.buckets[i].Load()
+		}
+	}
+	return snapshot
+}
+
+// 每隔 liveInterval 向标准错误输出最近一个间隔内的QPS、成功率和p95,返回停止输出的函数
+func startLiveReport(collector *metricsCollector) func() {
+	ticker := time.NewTicker(liveInterval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		last := collector.snapshot()
+		lastTime := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				current := collector.snapshot()
+				requests := current.requests - last.requests
+				success := current.success - last.success
+				buckets := make([]int64, len(current.buckets))
+				for i := range buckets {
+					buckets[i] = current.buckets[i] - last.buckets[i]
+				}
+				elapsed := now.Sub(lastTime).Seconds()
+				p95 := time.Duration(histogramQuantile(0.95, buckets) * float64(time.Second))
+				fmt.Fprintf(os.Stderr, tr("live_report"), float64(requests)/elapsed, ratioPercent(success, requests), p95.Round(time.Millisecond), current.requests)
+				last, lastTime = current, now
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+	}
+}
+
+// 根据耗时直方图估算分位数,单位:秒,在所在区间内线性插值,与 Prometheus 的 histogram_quantile 一致
+// 落在 +Inf 区间时返回最大的区间上限,没有请求时返回0
+func histogramQuantile(q float64, buckets []int64) float64 {
+	var total int64
+	for _, count := range buckets {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	rank := q * float64(total)
+	var cumulative int64
+	for i, count := range buckets {
+		if count == 0 || float64(cumulative+count) < rank {
+			cumulative += count
+			continue
+		}
+		if i == len(metricsBuckets) {
+			return metricsBuckets[len(metricsBuckets)-1]
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = metricsBuckets[i-1]
+		}
+		return lower + (metricsBuckets[i]-lower)*(rank-float64(cumulative))/float64(count)
+	}
+	return metricsBuckets[len(metricsBuckets)-1]
+}
@@ -1,17 +1,30 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"maps"
+	"math/rand/v2"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/cheggaaa/pb/v3"
+	"github.com/joho/godotenv"
 	"github.com/tidwall/gjson"
 )
 
@@ -20,60 +33,620 @@ type Result struct {
 	RequestConfig RequestConfig
 	// URL               string
 	// Method            string
-	TotalRequests     int64
-	SuccessRequests   int64
-	TotalTime         int64
-	MaxTime           int64
-	AvgTime           int64
-	RequestsTimes     []int64
+	TotalRequests   int64
+	SuccessRequests int64
+	TotalTime       int64
+	MaxTime         int64
+	MinTime         int64
+	AvgTime         int64
+	// 耗时的总体标准差
+	StdDevTime int64
+	// 耗时百分位数(nearest-rank),没有请求耗时记录时为0
+	P50Time       int64
+	P90Time       int64
+	P95Time       int64
+	P99Time       int64
+	RequestsTimes []int64
+	// -f 为目录时该配置所在的文件名
+	ConfigFile string `json:",omitempty"`
+	// 冒烟检查失败的原因,失败时该配置不进行压测
+	SmokeError string `json:",omitempty"`
+	// 准备(数据行替换、模板求值)和构建请求的平均耗时(客户端开销),单位:微秒
+	AvgClientOverheadUs int64
+	// 同时等待响应的请求数峰值,明显小于并发数时说明服务端处理及时,可以继续提高并发
+	MaxInFlight int64
+	// 每秒的请求数和耗时百分位数,仅在 -timeseries 时记录
+	TimeSeries []SecondStat `json:",omitempty"`
+	// 建立连接各阶段(DNS解析、建立TCP连接、TLS握手)的耗时统计,仅在 -trace 时记录
+	DNSStat     PhaseStat
+	ConnectStat PhaseStat
+	TLSStat     PhaseStat
+	// 建立WebSocket连接的握手耗时统计,握手耗时不计入请求耗时
+	HandshakeStat PhaseStat `json:",omitempty"`
+	// 首字节耗时,仅在 -trace 时记录
+	TTFBTimes         []int64 `json:",omitempty"`
+	AvgTTFBTime       int64   `json:",omitempty"`
+	MaxTTFBTime       int64   `json:",omitempty"`
+	P50TTFBTime       int64   `json:",omitempty"`
+	P95TTFBTime       int64   `json:",omitempty"`
+	P99TTFBTime       int64   `json:",omitempty"`
 	RequestTimeoutNum int64
-	ErrorCodes        map[int]int
-	ErrorMessages     map[string]int
+	// 建立TCP连接失败的请求数,如服务端连接队列已满
+	ConnectFailures int64
+	// 被 -client-chaos 随机中止的请求数
+	ClientAborted int64
+	// 状态码正确但响应内容校验失败的请求数
+	ValidationFailures int64
+	// 未收到完整响应的请求数(超时除外),如连接失败、读取响应体失败
+	NetworkErrors int64
+	// 按 RetryOn 状态码重试的次数
+	RetryCount int64
+	// 混合模式下因更高优先级的请求先领取令牌而被推迟的请求数
+	PriorityDelayed int64
+	// 重复发送的幂等请求与首次响应不一致的次数
+	IdempotencyViolations int64
+	// 单调字段递减次数
+	MonotonicViolations int64
+	ErrorCodes          map[int]int
+	ErrorMessages       map[string]int
+	// 连接失败和网络错误按 DNS解析失败(dns)、连接被拒绝(refused)、TLS错误(tls)、连接被重置或提前关闭(reset)、其他(other) 分类的次数
+	ErrorCategories map[string]int `json:",omitempty"`
+	// 状态码错误的响应内容(前 -error-body 字节),按次数从高到低排列
+	ErrorBodies []ErrorBody `json:",omitempty"`
+	// 统计过程中的错误响应内容及次数,finish 时排序后保存到 ErrorBodies
+	errorBodies map[errorBodyKey]int
+	// 响应体总字节数,用于计算平均响应大小和吞吐量
+	TotalBytes int64
+	// 实际发送的请求体压缩前后的平均字节数,仅在启用请求体压缩时记录
+	RequestBodySize    int64 `json:",omitempty"`
+	CompressedBodySize int64 `json:",omitempty"`
+	// 统计过程中压缩的请求数和压缩前后的总字节数,finish 时计算平均值
+	compressedRequests int64
+	requestBodyBytes   int64
+	compressedBytes    int64
+	// 首个状态码正确的响应的结构,仅在 -capture-shape 时记录
+	Shape []string `json:",omitempty"`
+	// 按请求方法统计的结果,仅在配置了 Methods 时记录
+	MethodResults map[string]*MethodResult `json:",omitempty"`
+	// 耗时最长的请求,按耗时从高到低排列,仅在 -slowest 时记录
+	Slowest []SlowRequest `json:",omitempty"`
+	// 统计过程中的最慢请求,finish 时排序后保存到 Slowest
+	slowest slowestHeap
 }
 
+// 创建请求配置的空结果
+func newResult(request RequestConfig) Result {
+	return Result{
+		RequestConfig:   request,
+		ConfigFile:      request.sourceFile,
+		ErrorCodes:      make(map[int]int),
+		ErrorMessages:   make(map[string]int),
+		ErrorCategories: make(map[string]int),
+		MethodResults:   make(map[string]*MethodResult),
+	}
+}
+
+// 合并工作协程的统计结果,Shape 保留最先记录的结构
+func (r *Result) merge(other *Result) {
+	r.TotalRequests += other.TotalRequests
+	r.SuccessRequests += other.SuccessRequests
+	r.RequestsTimes = append(r.RequestsTimes, other.RequestsTimes...)
+	r.TTFBTimes = append(r.TTFBTimes, other.TTFBTimes...)
+	r.compressedRequests += other.compressedRequests
+	r.requestBodyBytes += other.requestBodyBytes
+	r.compressedBytes += other.compressedBytes
+	r.DNSStat.merge(other.DNSStat)
+	r.ConnectStat.merge(other.ConnectStat)
+	r.TLSStat.merge(other.TLSStat)
+	r.HandshakeStat.merge(other.HandshakeStat)
+	r.RequestTimeoutNum += other.RequestTimeoutNum
+	r.ConnectFailures += other.ConnectFailures
+	r.ClientAborted += other.ClientAborted
+	r.ValidationFailures += other.ValidationFailures
+	r.NetworkErrors += other.NetworkErrors
+	r.RetryCount += other.RetryCount
+	r.PriorityDelayed += other.PriorityDelayed
+	r.IdempotencyViolations += other.IdempotencyViolations
+	r.MonotonicViolations += other.MonotonicViolations
+	r.TotalBytes += other.TotalBytes
+	for _, request := range other.slowest {
+		r.slowest.add(request)
+	}
+	for code, count := range other.ErrorCodes {
+		r.ErrorCodes[code] += count
+	}
+	for message, count := range other.ErrorMessages {
+		r.ErrorMessages[message] += count
+	}
+	for category, count := range other.ErrorCategories {
+		r.ErrorCategories[category] += count
+	}
+	for key, count := range other.errorBodies {
+		r.addErrorBodyCount(key, count)
+	}
+	if r.Shape == nil {
+		r.Shape = other.Shape
+	}
+	for method, methodResult := range other.MethodResults {
+		merged := r.MethodResults[method]
+		if merged == nil {
+			merged = &MethodResult{}
+			r.MethodResults[method] = merged
+		}
+		merged.TotalRequests += methodResult.TotalRequests
+		merged.SuccessRequests += methodResult.SuccessRequests
+	}
+}
+
+// 连接阶段耗时统计,只统计实际进行了该阶段的请求,复用连接的请求不计入
+type PhaseStat struct {
+	Count int64
+	// 平均耗时,单位:微秒
+	AvgUs   int64
+	totalUs int64
+}
+
+// 记录一次阶段耗时,耗时为0表示该请求没有进行该阶段
+func (s *PhaseStat) add(d time.Duration) {
+	if d > 0 {
+		s.Count++
+		s.totalUs += d.Microseconds()
+	}
+}
+
+// 合并其他协程的统计并重新计算平均耗时
+func (s *PhaseStat) merge(other PhaseStat) {
+	s.Count += other.Count
+	s.totalUs += other.totalUs
+	if s.Count > 0 {
+		s.AvgUs = s.totalUs / s.Count
+	}
+}
+
+// 一秒内完成的请求统计
+type SecondStat struct {
+	Second   int64
+	Requests int64
+	P50Time  int64
+	P95Time  int64
+	MaxTime  int64
+}
+
+// 单个请求方法的统计结果
+type MethodResult struct {
+	TotalRequests   int64
+	SuccessRequests int64
+}
+
+// 耗时分布统计的区间大小,单位:毫秒,通过 -bucket 指定
+var distributionInterval int64 = 100
+
+// 0ms样本占比超过该值时提示计时精度不足
+const zeroLatencyWarnRatio = 0.5
+
 var debug bool
 var configFileName string
 
+// 结果文件格式,通过 -o 指定,可选 json、csv、html,多个格式用逗号分隔
+var outputFormats = []string{"json"}
+
+// 支持的结果文件格式
+var supportedOutputFormats = []string{"json", "csv", "html"}
+
+// 静默模式,不输出测试过程信息和进度条
+var quiet bool
+
+// 随机中止请求的比例,通过 -client-chaos 指定
+var clientChaos float64
+
+// 被随机中止的请求在发出后多久内取消
+const clientChaosMaxDelay = 100 * time.Millisecond
+
+// 是否在压测每个配置前进行冒烟检查
+var smokeCheck bool
+
+// 冒烟检查失败后的重试次数和间隔
+var smokeRetries int
+var smokeInterval time.Duration
+
+// 默认的最低成功QPS,通过 -min-qps 指定,0表示不检查
+var defaultMinQPS float64
+
+// 允许的最大失败率(百分比),通过 -max-error-rate 指定,小于0表示不检查
+var maxErrorRate float64
+
+// 允许的最大p95耗时,通过 -max-p95 指定,0表示不检查
+var maxP95 time.Duration
+
+// 是否记录每秒的请求数和耗时百分位数
+var timeSeries bool
+
+// 是否通过 httptrace 记录请求各阶段耗时
+var traceRequest bool
+
+// 是否记录响应结构,通过 -capture-shape 指定保存路径时启用
+var captureShape bool
+
+// 并发协程的预热启动时间,通过 -rampup 指定,0表示所有协程同时启动
+var rampUp time.Duration
+
+// 每个请求配置压测前发送的预热请求数,通过 -warmup 指定,预热请求不计入结果
+var warmupRequests int64
+
+// 每个请求配置的运行时长,通过 -duration 指定,大于0时忽略 -n
+var testDuration time.Duration
+
+// 阶梯并发模式下每个并发数的运行时长,通过 -step-duration 指定
+var stepDuration time.Duration
+
+// 整个运行的上下文,收到 Ctrl-C/SIGTERM 时被取消
+var runCtx = context.Background()
+
+// 是否同时运行所有请求配置
+var parallelConfigs bool
+
+// 最近一次 runTest 运行所有请求配置的总耗时(墙上时间),用于输出汇总
+var testElapsed time.Duration
+
+// 是否以混合模式运行,所有请求配置共用工作协程并按权重分配请求
+var mixedConfigs bool
+
+// 同时运行多个请求配置时共用的进度通道
+var sharedProgress chan<- struct{}
+
 func main() {
+	startTime := time.Now()
 	// 命令行参数解析
 	concurrency := flag.Int64("c", 100, "并发数")
 	totalRequests := flag.Int64("n", 1000, "总请求数")
-	configFile := flag.String("f", "config.json", "URL配置文件路径")
+	configFile := flag.String("f", "config.json", "URL配置文件路径,为 - 时从标准输入读取JSON配置,为目录时依次运行目录中所有 .json/.yaml/.yml 文件的配置")
 	timeout := flag.Int64("t", 20, "超时时间")
 	isDebug := flag.Bool("d", false, "是否开启调试模式")
+	outputLang := flag.String("lang", "zh", "输出语言: zh|en")
+	outputFormat := flag.String("o", "json", "结果文件格式: json|csv|html,多个格式用逗号分隔,如 json,html")
+	notifyWebhookURL := flag.String("notify-webhook", "", "运行结束后推送汇总信息的webhook地址")
+	notifyTemplate := flag.String("notify-template", "", "推送汇总信息使用的 text/template 模板,为空时使用默认markdown模板")
+	manifestFile := flag.String("manifest", "", "运行清单输出路径,记录所有参数、配置文件哈希、版本和起止时间,为空时不输出")
+	junitFile := flag.String("junit", "", "JUnit XML报告输出路径,为空时不输出")
+	flag.BoolVar(&strictEnv, "strict-env", false, "配置文件中引用的环境变量未设置时报错,默认替换为空字符串")
+	envFile := flag.String("env-file", "", "加载环境变量文件(.env格式),已存在的环境变量优先")
+	captureShapeFile := flag.String("capture-shape", "", "记录每个配置的响应结构并保存到该文件,作为 -assert-shape 的基准")
+	assertShapeFile := flag.String("assert-shape", "", "从该文件加载基准响应结构,校验每个响应的key结构是否一致")
+	distCSVFile := flag.String("dist-csv", "", "耗时分布CSV文件输出路径,为空时不输出")
+	steps := flag.String("steps", "", "阶梯并发数列表,如 10,50,100,每个配置在每个并发数下运行 -step-duration 时长并输出并发数与QPS、p95的对应表")
+	flag.DurationVar(&stepDuration, "step-duration", 10*time.Second, "阶梯并发模式下每个并发数的运行时长,如 10s、1m,在截止时间前持续发送请求,忽略 -n 和 -duration")
+	canary := flag.Bool("canary", false, "持续监测模式,每隔 -interval 运行一次测试,仅在未通过时告警")
+	webhook := flag.String("webhook", "", "持续监测模式下未通过时推送告警的webhook地址")
+	interval := flag.Duration("interval", time.Minute, "持续监测模式的测试间隔")
+	flag.DurationVar(&rampUp, "rampup", 0, "并发协程的预热启动时间,如 10s,在该时间内均匀地逐个启动协程,0表示同时启动")
+	flag.Int64Var(&warmupRequests, "warmup", 0, "每个请求配置压测前发送的预热请求数,预热请求不计入结果,0表示不预热")
+	think := flag.String("think", "", "每个协程完成一个请求后开始下一个请求前的思考时间,如 200ms 或范围 100-500ms(每次随机),不计入请求耗时,为空时不等待")
+	flag.Int64Var(&requestRate, "rate", 0, "每个请求配置每秒最多发送的请求数,所有并发协程共用,0表示不限速")
+	flag.DurationVar(&testDuration, "duration", 0, "每个请求配置的运行时长,如 30s、10m,设置后忽略 -n,在截止时间前持续发送请求")
+	flag.Float64Var(&clientChaos, "client-chaos", 0, "随机中止请求的比例(0-1),被选中的请求会在随机延迟后取消,模拟客户端提前断开")
+	flag.BoolVar(&smokeCheck, "smoke", false, "压测每个配置前先发送单个请求进行冒烟检查,失败时跳过该配置")
+	flag.IntVar(&smokeRetries, "smoke-retries", 0, "冒烟检查失败后的重试次数")
+	flag.DurationVar(&smokeInterval, "smoke-interval", 5*time.Second, "冒烟检查重试间隔")
+	flag.Float64Var(&defaultMinQPS, "min-qps", 0, "每个配置的最低成功QPS,低于该值时程序以非0状态码退出,0表示不检查")
+	flag.Float64Var(&maxErrorRate, "max-error-rate", -1, "每个配置允许的最大失败率(百分比),如 1 表示1%,超过时程序以非0状态码退出,小于0表示不检查")
+	flag.DurationVar(&maxP95, "max-p95", 0, "每个配置允许的最大p95耗时,如 500ms,超过时程序以非0状态码退出,0表示不检查")
+	flag.BoolVar(&timeSeries, "timeseries", false, "是否统计并输出每秒的请求数和耗时百分位数(p50/p95/max)")
+	flag.BoolVar(&traceRequest, "trace", false, "是否记录请求各阶段耗时(首字节耗时等),会带来额外开销")
+	flag.BoolVar(&parallelConfigs, "parallel-configs", false, "是否同时运行所有请求配置,并发数在各配置间平均分配")
+	flag.BoolVar(&mixedConfigs, "mixed", false, "混合模式,所有请求配置共用 -c 个并发和 -n 个请求,每个请求按配置的 weight 随机选择配置")
+	bind := flag.String("bind", "", "发起连接使用的本地IP地址或网卡名称,多个用逗号分隔时每个新连接轮流使用,用于多网卡压测机分散流量,为空时由系统选择")
+	proxy := flag.String("proxy", "", "代理地址,支持 http、https、socks5,如 http://127.0.0.1:8080,请求配置中的 proxy 优先")
+	flag.IntVar(&maxIdleConns, "max-idle-conns", 0, "所有主机的最大空闲连接数,0表示使用默认值100")
+	flag.IntVar(&maxIdleConnsPerHost, "max-idle-conns-per-host", 0, "每个主机的最大空闲连接数,0表示使用默认值2,高并发时建议设置为不小于 -c")
+	flag.BoolVar(&disableKeepAlives, "no-keepalive", false, "禁用长连接,每个请求都建立新连接")
+	flag.BoolVar(&http10, "http10", false, "是否以 HTTP/1.0 发送请求,每个请求使用独立连接")
+	samplesFile := flag.String("samples", "", "逐个请求记录配置序号、开始时间、耗时、状态码和错误,以NDJSON格式在测试过程中写入该文件,为空时不记录")
+	exportHAR := flag.String("export-har", "", "按 -har-sample 比例抽样记录请求和响应并导出为HAR文件,为空时不记录")
+	flag.Float64Var(&harSample, "har-sample", 0.01, "导出HAR文件时抽样记录的请求比例(0-1)")
+	flag.Int64Var(&distributionInterval, "bucket", 100, "耗时分布统计的区间大小,单位:毫秒,如 1 表示按1ms统计")
+	flag.IntVar(&errorBodyBytes, "error-body", 200, "状态码错误时记录响应体的前N字节,相同内容合并计数并在结果中输出,0表示不记录")
+	flag.IntVar(&repeatRuns, "repeat", 1, "完整运行所有请求配置的次数,大于1时在结果后输出各次运行的QPS、p95及其最小值、最大值和平均值的对比")
+	flag.IntVar(&slowestRequests, "slowest", 0, "记录并输出每个配置耗时最长的N个请求及其实际请求地址,0表示不记录")
+	flag.BoolVar(&liveReport, "live", false, "测试过程中每秒向标准错误输出最近1秒的QPS、成功率和p95,代替进度条")
+	metricsAddr := flag.String("metrics-addr", "", "Prometheus指标服务监听地址,如 :9090,测试期间通过 /metrics 提供实时指标,为空时不启动")
+	flag.BoolVar(&noRedirect, "no-redirect", false, "不跟随重定向,直接校验原始的3xx响应,请求配置中的 followRedirects 优先")
+	flag.BoolVar(&useCookieJar, "cookies", false, "是否保存响应设置的Cookie并在后续请求中发送,同一配置的所有并发协程共用一个会话")
+	insecure := flag.Bool("insecure", false, "跳过HTTPS证书校验,仅用于测试使用自签名证书的服务")
+	certFile := flag.String("cert", "", "HTTPS客户端证书文件路径(PEM),需要与 -key 一起使用")
+	keyFile := flag.String("key", "", "HTTPS客户端证书私钥文件路径(PEM),需要与 -cert 一起使用")
+	flag.BoolVar(&noDefaultHeaders, "no-default-headers", false, "不发送默认的 User-Agent、Accept、Accept-Language 请求头,请求配置中的 noDefaultHeaders 可以单独设置")
+	flag.StringVar(&userAgent, "user-agent", "", "所有请求使用的 User-Agent,为空时使用内置的浏览器 User-Agent,请求配置 headers 中的 User-Agent 优先")
+	flag.BoolVar(&dryRun, "dry-run", false, "只输出每个配置解析后的第一个请求(方法、最终URL、请求头和请求体),不发送任何请求")
+	flag.BoolVar(&compressRequest, "compress-request", false, "是否使用gzip压缩所有请求的请求体")
 	flag.Parse()
 	debug = *isDebug
+	if !slices.Contains(supportedLangs, *outputLang) {
+		fmt.Printf(tr("unsupported_lang"), *outputLang, supportedLangs)
+		return
+	}
+	lang = *outputLang
+	outputFormats = strings.Split(*outputFormat, ",")
+	for _, format := range outputFormats {
+		if !slices.Contains(supportedOutputFormats, format) {
+			fmt.Printf(tr("unsupported_output_format"), format, supportedOutputFormats)
+			return
+		}
+	}
+	// 校验数值参数必须为正数
+	if *concurrency <= 0 || *totalRequests <= 0 || *timeout <= 0 {
+		fmt.Printf(tr("invalid_flags"), *concurrency, *totalRequests, *timeout)
+		return
+	}
+	if rampUp < 0 {
+		fmt.Printf(tr("invalid_rampup"), rampUp)
+		return
+	}
+	if distributionInterval <= 0 {
+		fmt.Printf(tr("invalid_bucket"), distributionInterval)
+		return
+	}
+	if errorBodyBytes < 0 {
+		fmt.Printf(tr("invalid_error_body"), errorBodyBytes)
+		return
+	}
+	if repeatRuns < 1 {
+		fmt.Printf(tr("invalid_repeat"), repeatRuns)
+		return
+	}
+	if slowestRequests < 0 {
+		fmt.Printf(tr("invalid_slowest"), slowestRequests)
+		return
+	}
+	if warmupRequests < 0 {
+		fmt.Printf(tr("invalid_warmup"), warmupRequests)
+		return
+	}
+	if requestRate < 0 {
+		fmt.Printf(tr("invalid_rate"), requestRate)
+		return
+	}
+	if testDuration < 0 {
+		fmt.Printf(tr("invalid_duration"), testDuration)
+		return
+	}
+	if stepDuration <= 0 {
+		fmt.Printf(tr("invalid_step_duration"), stepDuration)
+		return
+	}
+	if maxErrorRate > 100 || maxP95 < 0 {
+		fmt.Printf(tr("invalid_thresholds"), maxErrorRate, maxP95)
+		return
+	}
+	if maxIdleConns < 0 || maxIdleConnsPerHost < 0 {
+		fmt.Printf(tr("invalid_idle_conns"), maxIdleConns, maxIdleConnsPerHost)
+		return
+	}
+	if mixedConfigs && parallelConfigs {
+		fmt.Print(tr("invalid_mixed"))
+		return
+	}
+	if smokeRetries < 0 {
+		fmt.Printf(tr("invalid_smoke_retries"), smokeRetries)
+		return
+	}
+	if *think != "" {
+		var err error
+		if thinkTime, err = parseThinkTime(*think); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if clientChaos < 0 || clientChaos > 1 {
+		fmt.Printf(tr("invalid_client_chaos"), clientChaos)
+		return
+	}
+	if harSample < 0 || harSample > 1 {
+		fmt.Printf(tr("invalid_har_sample"), harSample)
+		return
+	}
+	config, err := newTLSConfig(*insecure, *certFile, *keyFile)
+	if err != nil {
+		fmt.Printf(tr("load_cert_failed"), err)
+		return
+	}
+	tlsConfig = config
+	if *proxy != "" {
+		if proxyURL, err = parseProxyURL(*proxy); err != nil {
+			fmt.Printf(tr("invalid_proxy"), err)
+			return
+		}
+	}
+	if *bind != "" {
+		if bindAddrs, err = parseBindAddrs(*bind); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	// 加载环境变量文件,godotenv.Load 不会覆盖已存在的环境变量
+	if *envFile != "" {
+		if err := godotenv.Load(*envFile); err != nil {
+			fmt.Printf(tr("load_env_file_failed"), *envFile, err)
+			return
+		}
+	}
 	configFileName = filepath.Base(*configFile)
+	// 从标准输入读取配置时结果文件命名为 result.stdin
+	if *configFile == stdinConfigFile {
+		configFileName = "stdin"
+	}
 	// 读取配置文件
 	requestList, err := ReadConfig(*configFile)
 	if err != nil {
-		fmt.Printf("读取配置文件%s失败: %v\n", *configFile, err)
+		fmt.Printf(tr("read_config_failed"), *configFile, err)
 		return
 	}
 
+	// 收到 Ctrl-C/SIGTERM 时取消 runCtx,工作协程停止领取新请求,输出已完成请求的结果
+	// 第一次中断后恢复默认的信号处理,再次按 Ctrl-C 时立即退出
+	ctx, stopSignal := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	runCtx = ctx
+	go func() {
+		<-ctx.Done()
+		stopSignal()
+		fmt.Print(tr("interrupted"))
+	}()
+
+	// 清理配置不参与压测,在测试完成后或被中断时执行
+	requestList, teardowns := splitTeardown(requestList)
 	if len(requestList) == 0 {
-		fmt.Println("配置文件中未找到请求配置")
+		fmt.Print(tr("no_request_config"))
 		return
 	}
+	if dryRun {
+		runDryRun(requestList, *timeout)
+		return
+	}
+	teardown := newTeardownRunner(teardowns, *timeout)
+
+	if *assertShapeFile != "" {
+		if err := loadShapes(*assertShapeFile, requestList); err != nil {
+			fmt.Printf(tr("load_shape_failed"), *assertShapeFile, err)
+			return
+		}
+	}
+	captureShape = *captureShapeFile != ""
+
+	// 测试期间提供实时指标,所有配置完成后关闭
+	stopMetrics := func() {}
+	if *metricsAddr != "" {
+		if stopMetrics, err = startMetricsServer(*metricsAddr); err != nil {
+			fmt.Printf(tr("metrics_server_failed"), *metricsAddr, err)
+			return
+		}
+	}
+	// 实时输出与指标服务共用指标,未启动指标服务时单独记录
+	if liveReport {
+		if liveMetrics == nil {
+			liveMetrics = &metricsCollector{}
+		}
+		stopLive := startLiveReport(liveMetrics)
+		stopServer := stopMetrics
+		stopMetrics = func() {
+			stopLive()
+			stopServer()
+		}
+	}
+
+	if *steps != "" {
+		levels, err := parseSteps(*steps)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		runSteps(requestList, levels, *timeout)
+		stopMetrics()
+		teardown.Run()
+		return
+	}
+
+	if *canary {
+		if *interval <= 0 {
+			fmt.Printf(tr("invalid_interval"), *interval)
+			return
+		}
+		runCanary(requestList, *concurrency, *totalRequests, *timeout, *interval, *webhook)
+		stopMetrics()
+		teardown.Run()
+		return
+	}
+
+	if *exportHAR != "" {
+		harRecorder = newHARLog()
+	}
+	if *samplesFile != "" {
+		if sampleRecorder, err = newSampleWriter(*samplesFile); err != nil {
+			fmt.Printf(tr("write_file_failed"), *samplesFile, err)
+			return
+		}
+	}
 
 	// 运行压力测试
-	results := runTest(requestList, *concurrency, *totalRequests, *timeout)
+	runs := runRepeated(requestList, *concurrency, *totalRequests, *timeout)
+	// 结果文件、阈值检查和通知使用最后一次运行的结果
+	results := runs[len(runs)-1]
+	stopMetrics()
+	if sampleRecorder != nil {
+		if err := sampleRecorder.Close(); err != nil {
+			fmt.Printf(tr("write_file_failed"), *samplesFile, err)
+		}
+	}
+	teardown.Run()
 
 	// 计算并显示结果
 	showResult(results)
+	showRepeatComparison(runs)
+
+	if captureShape {
+		if err := saveShapes(*captureShapeFile, results); err != nil {
+			fmt.Printf(tr("write_file_failed"), *captureShapeFile, err)
+		}
+	}
+	if *distCSVFile != "" {
+		if err := writeDistributionCSV(*distCSVFile, results); err != nil {
+			fmt.Printf(tr("write_file_failed"), *distCSVFile, err)
+		}
+	}
+	if harRecorder != nil {
+		if err := harRecorder.Save(*exportHAR); err != nil {
+			fmt.Printf(tr("write_file_failed"), *exportHAR, err)
+		}
+	}
+	if *junitFile != "" {
+		if err := writeJUnit(*junitFile, results); err != nil {
+			fmt.Printf(tr("write_junit_failed"), *junitFile, err)
+		}
+	}
+	if *notifyWebhookURL != "" {
+		if err := notifyWebhook(*notifyWebhookURL, *notifyTemplate, results, *timeout); err != nil {
+			fmt.Printf(tr("webhook_failed"), *notifyWebhookURL, err)
+		}
+	}
+
+	if *manifestFile != "" {
+		if err := writeManifest(*manifestFile, *configFile, startTime, time.Now()); err != nil {
+			fmt.Printf(tr("write_file_failed"), *manifestFile, err)
+		}
+	}
+
+	// 被中断时以130状态码退出,与 shell 中 Ctrl-C 中止程序的状态码一致
+	if runCtx.Err() != nil {
+		os.Exit(130)
+	}
+	// 存在超出阈值的配置时以非0状态码退出,便于在CI中使用
+	if reportThresholds(results) {
+		os.Exit(1)
+	}
 }
 
 // 运行压力测试
 func runTest(requestList []RequestConfig, concurrency, totalRequests, timeout int64) []Result {
+	requestList = prepareChain(requestList, timeout)
+	testStartTime := time.Now()
+	defer func() {
+		testElapsed = time.Since(testStartTime)
+	}()
+	if mixedConfigs {
+		return runMixedTest(requestList, concurrency, totalRequests, timeout)
+	}
+	if parallelConfigs && len(requestList) > 1 {
+		return runParallelTest(requestList, concurrency, totalRequests, timeout)
+	}
 	var results []Result
 
-	// 顺序处理每个请求配置
+	// 顺序处理每个请求配置,被中断时不再运行后面的配置
 	for index, request := range requestList {
-		fmt.Printf("开始测试请求配置 #%d: [%s] %s\n", index+1, request.Method, request.URL)
-		if request.Response.Status == 0 {
-			request.Response.Status = http.StatusOK
+		if runCtx.Err() != nil {
+			break
+		}
+		if !quiet {
+			fmt.Printf(tr("start_test"), index+1, request.Method, request.URL)
 		}
-		reqResult := runSingleConfigTest(request, concurrency, totalRequests, timeout)
+		reqResult := runSingleConfigTest(index, request, concurrency, totalRequests, timeout, testDuration)
 
 		results = append(results, reqResult)
 		// fmt.Printf("测试完成 #%d: 总请求数=%d, 成功数=%d, 总耗时=%vms\n\n", index+1, reqResult.TotalRequests, reqResult.SuccessRequests, reqResult.TotalTime)
@@ -81,178 +654,902 @@ func runTest(requestList []RequestConfig, concurrency, totalRequests, timeout in
 	return results
 }
 
-// 运行单个请求配置的压力测试
-func runSingleConfigTest(request RequestConfig, concurrency, totalRequests, timeout int64) Result {
+// 同时运行所有请求配置,并发数在各配置间平均分配,结果按配置顺序返回
+func runParallelTest(requestList []RequestConfig, concurrency, totalRequests, timeout int64) []Result {
+	var wg sync.WaitGroup
+	results := make([]Result, len(requestList))
+
+	// 所有配置共用一个总进度条,避免多个进度条相互覆盖
+	count := int64(len(requestList))
+	var progressSum int64
+	for _, request := range requestList {
+		progressSum += progressTotal(cmp.Or(request.TotalRequests, totalRequests), testDuration)
+	}
+	progress, finishProgress := startProgress(progressSum)
+	sharedProgress = progress
+	for index, request := range requestList {
+		if !quiet {
+			fmt.Printf(tr("start_test"), index+1, request.Method, request.URL)
+		}
+		// 平均分配并发数,余数分给靠前的配置,每个配置至少1个并发
+		configConcurrency := concurrency / count
+		if int64(index) < concurrency%count {
+			configConcurrency++
+		}
+		configConcurrency = max(configConcurrency, 1)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[index] = runSingleConfigTest(index, request, configConcurrency, totalRequests, timeout, testDuration)
+		}()
+	}
+	wg.Wait()
+	sharedProgress = nil
+	finishProgress()
+	return results
+}
+
+// 运行单个请求配置的压力测试,index 为配置序号(从0开始),duration 大于0时在该时长内持续发送请求并忽略 totalRequests
+func runSingleConfigTest(index int, request RequestConfig, concurrency, totalRequests, timeout int64, duration time.Duration) Result {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
-	result := Result{
-		RequestConfig: request,
-		ErrorCodes:    make(map[int]int),
-		ErrorMessages: make(map[string]int),
+	result := newResult(request)
+
+	// 配置中指定的并发数和总请求数优先于命令行参数
+	if request.Concurrency > 0 {
+		concurrency = request.Concurrency
+	}
+	if request.TotalRequests > 0 {
+		totalRequests = request.TotalRequests
 	}
 
-	// 初始化请求处理器
-	handler := NewRequestHandler(time.Duration(timeout) * time.Second)
+	// 初始化请求处理器,配置中指定的超时时间和代理优先于 -t 和 -proxy
+	handler := newConfigHandler(request, timeout)
+	defer handler.Close()
+
+	// 压测前的冒烟检查,失败时跳过该配置
+	if smokeCheck {
+		if err := runSmokeCheck(runCtx, handler, request); err != nil {
+			result.SmokeError = err.Error()
+			if !quiet {
+				fmt.Printf(tr("smoke_failed"), err)
+			}
+			return result
+		}
+	}
 
-	// startTime := time.Now()
-	requestChan := make(chan struct{}, totalRequests)
+	// 每个配置使用独立的限速器,所有工作协程共用
+	limiter := newRateLimiter(requestRate)
+	defer limiter.Stop()
 
-	// 填充请求通道
-	for range totalRequests {
-		requestChan <- struct{}{}
+	// 预热请求建立连接后丢弃结果,不计入耗时和请求数,也不占用 -duration 的运行时长
+	if warmupRequests > 0 {
+		runWarmup(runCtx, handler, limiter, request, concurrency, warmupRequests)
 	}
-	close(requestChan)
 
-	bar := pb.StartNew(int(totalRequests))
+	quota, cancel := newRequestQuota(totalRequests, duration)
+	defer cancel()
+
+	// 启用 -metrics-addr 时实时更新的指标
+	metrics := liveMetrics.forConfig(request)
+
+	progress, finishProgress := startProgress(progressTotal(totalRequests, duration))
+	// 构建请求的总耗时
+	var totalClientOverhead time.Duration
+	// 按完成时间所在秒统计的请求耗时,仅在 -timeseries 时记录
+	secondTimes := make(map[int64][]int64)
 	totalStartTime := time.Now()
-	// 创建工作协程
-	for range concurrency {
+	run := &configRun{index: index, request: request, handler: handler, metrics: metrics, progress: progress, startTime: totalStartTime, ctx: quota.ctx}
+	startWorkers(&wg, quota, concurrency, func() {
+		stats := newWorkerStats(request)
+		defer func() {
+			mu.Lock()
+			result.merge(&stats.result)
+			totalClientOverhead += stats.clientOverhead
+			for second, times := range stats.secondTimes {
+				secondTimes[second] = append(secondTimes[second], times...)
+			}
+			mu.Unlock()
+		}()
+		for quota.next() {
+			// 限速时先获取令牌,等待时间不计入请求耗时
+			if !limiter.Wait(quota.ctx) {
+				break
+			}
+			run.send(stats)
+			// 思考时间在请求之间等待,不计入请求耗时,最后一个请求后不等待
+			if !quota.exhausted() {
+				thinkTime.wait(quota.ctx)
+			}
+		}
+	})
+
+	wg.Wait()
+	finishProgress()
+	result.finish(time.Since(totalStartTime), totalClientOverhead, secondTimes)
+	result.MaxInFlight = run.maxInFlight.Load()
+
+	return result
+}
+
+// 计算汇总统计: 总耗时、耗时统计、百分位数、客户端开销和时间序列
+func (r *Result) finish(totalTime, clientOverhead time.Duration, secondTimes map[int64][]int64) {
+	r.TotalTime = totalTime.Milliseconds()
+	r.AvgTime = average(r.RequestsTimes)
+	r.MaxTime = maxDuration(r.RequestsTimes)
+	r.MinTime = minDuration(r.RequestsTimes)
+	r.StdDevTime = stdDevDuration(r.RequestsTimes)
+	r.P50Time = percentile(r.RequestsTimes, 50)
+	r.P90Time = percentile(r.RequestsTimes, 90)
+	r.P95Time = percentile(r.RequestsTimes, 95)
+	r.P99Time = percentile(r.RequestsTimes, 99)
+	if r.TotalRequests > 0 {
+		r.AvgClientOverheadUs = clientOverhead.Microseconds() / r.TotalRequests
+	}
+	r.TimeSeries = buildTimeSeries(secondTimes)
+	r.AvgTTFBTime = average(r.TTFBTimes)
+	if r.compressedRequests > 0 {
+		r.RequestBodySize = r.requestBodyBytes / r.compressedRequests
+		r.CompressedBodySize = r.compressedBytes / r.compressedRequests
+	}
+	r.MaxTTFBTime = maxDuration(r.TTFBTimes)
+	r.P50TTFBTime = percentile(r.TTFBTimes, 50)
+	r.P95TTFBTime = percentile(r.TTFBTimes, 95)
+	r.P99TTFBTime = percentile(r.TTFBTimes, 99)
+	r.Slowest = r.slowest.sorted()
+	r.ErrorBodies = r.sortedErrorBodies()
+}
+
+// 请求名额,按请求数运行时每个请求领取一个名额,按运行时长(-duration、-step-duration)运行时在截止时间前持续发送请求
+// 被中断时 runCtx 被取消,工作协程不再领取新请求
+type requestQuota struct {
+	ctx       context.Context
+	remaining atomic.Int64
+	// 运行时长,为0时按请求数运行
+	duration time.Duration
+}
+
+// 创建请求名额,duration 大于0时按运行时长运行,运行结束后需要调用返回的 cancel 释放计时器
+func newRequestQuota(totalRequests int64, duration time.Duration) (*requestQuota, context.CancelFunc) {
+	quota := &requestQuota{ctx: runCtx, duration: duration}
+	cancel := context.CancelFunc(func() {})
+	if duration > 0 {
+		quota.ctx, cancel = context.WithTimeout(runCtx, duration)
+	}
+	quota.remaining.Store(totalRequests)
+	return quota, cancel
+}
+
+// 领取一个名额,名额已用完、已到截止时间或被中断时返回 false
+func (q *requestQuota) next() bool {
+	if q.ctx.Err() != nil {
+		return false
+	}
+	return q.duration > 0 || q.remaining.Add(-1) >= 0
+}
+
+// 名额是否已用完或已到截止时间
+func (q *requestQuota) exhausted() bool {
+	return q.ctx.Err() != nil || (q.duration == 0 && q.remaining.Load() <= 0)
+}
+
+// 创建 concurrency 个工作协程运行 work,配置了 -rampup 时在预热时间内均匀地逐个启动
+func startWorkers(wg *sync.WaitGroup, quota *requestQuota, concurrency int64, work func()) {
+	rampUpInterval := rampUp / time.Duration(concurrency)
+	for worker := range concurrency {
+		if worker > 0 && rampUpInterval > 0 {
+			select {
+			case <-time.After(rampUpInterval):
+			case <-quota.ctx.Done():
+			}
+			// 请求已全部领取或已到截止时间时不再启动新的协程
+			if quota.exhausted() {
+				break
+			}
+		}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for range requestChan {
-				reqStartTime := time.Now()
-				// 使用请求处理器构建请求
-				resp, _, err := handler.NewRequest(request)
-				mu.Lock()
-				result.TotalRequests += 1
-				bar.Increment()
-				mu.Unlock()
+			work()
+		}()
+	}
+}
 
-				if err != nil {
-					// 判断超时
-					if err, ok := err.(net.Error); ok && err.Timeout() {
-						elapsed := time.Since(reqStartTime).Milliseconds() // 请求耗时,单位:毫秒
-						mu.Lock()
-						result.RequestTimeoutNum++
-						result.RequestsTimes = append(result.RequestsTimes, elapsed)
-						mu.Unlock()
-					} else {
-						mu.Lock()
-						result.ErrorMessages[err.Error()]++
-						mu.Unlock()
-					}
+// 单个请求配置在一次压测中所有工作协程共用的状态
+type configRun struct {
+	index     int
+	request   RequestConfig
+	handler   *RequestHandler
+	metrics   *configMetrics
+	progress  chan<- struct{}
+	startTime time.Time
+	// 请求名额的上下文,被中断或到达 -duration 截止时间时结束
+	ctx context.Context
+	// 正在等待响应的请求数及其峰值
+	inFlight    atomic.Int64
+	maxInFlight atomic.Int64
+}
 
-				} else {
-					// 确保响应体被读取和关闭,io.Discard 丢弃响应体内容
-					// io.Copy(io.Discard, resp.Body)
-
-					// 读取并打印内容
-					body, err := io.ReadAll(resp.Body)
-					resp.Body.Close()
-					elapsed := time.Since(reqStartTime).Milliseconds() // 请求耗时,单位:毫秒
-					mu.Lock()
-					result.RequestsTimes = append(result.RequestsTimes, elapsed)
-					mu.Unlock()
-
-					if err != nil {
-						mu.Lock()
-						result.ErrorMessages[fmt.Sprintf("读取响应体错误: %v", err)]++
-						mu.Unlock()
-						break
-					}
+// 请求发出前增加在途请求数并更新峰值
+func (c *configRun) beginRequest() {
+	n := c.inFlight.Add(1)
+	for {
+		peak := c.maxInFlight.Load()
+		if n <= peak || c.maxInFlight.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
 
-					if debug {
-						fmt.Printf("\n响应体内容: %s\n", string(body))
-					}
-					var statusFlag = false
-					if request.Response.Status == resp.StatusCode {
-						statusFlag = true
+// 收到响应或请求失败后减少在途请求数
+func (c *configRun) endRequest() {
+	c.inFlight.Add(-1)
+}
+
+// 工作协程对单个请求配置的本地统计,结束时统一合并到结果中,避免每个请求都争抢锁
+type workerStats struct {
+	result         Result
+	clientOverhead time.Duration
+	// 按完成时间所在秒统计的请求耗时,仅在 -timeseries 时记录
+	secondTimes map[int64][]int64
+	// 当前协程上一次读取到的单调字段值
+	lastMonotonic    float64
+	hasLastMonotonic bool
+}
+
+func newWorkerStats(request RequestConfig) *workerStats {
+	return &workerStats{
+		result:      newResult(request),
+		secondTimes: make(map[int64][]int64),
+	}
+}
+
+// 发送一个请求并将结果记录到工作协程的本地统计中
+func (c *configRun) send(w *workerStats) {
+	request, handler, metrics := c.request, c.handler, c.metrics
+	totalStartTime, progress := c.startTime, c.progress
+
+	// 数据行替换、模板求值等请求配置的准备耗时与构建请求的耗时一起计入客户端开销
+	renderStart := time.Now()
+	// 配置了数据文件时每个请求使用下一行数据,模板函数每个请求单独求值
+	reqConfig := request.nextRequest()
+	// 配置了多个请求方法时,先选定本次请求的方法以便分方法统计
+	var methodResult *MethodResult
+	if len(request.Methods) > 0 {
+		reqConfig.Method = handler.getMethod(request)
+		reqConfig.Methods = nil
+		methodResult = w.result.MethodResults[reqConfig.Method]
+		if methodResult == nil {
+			methodResult = &MethodResult{}
+			w.result.MethodResults[reqConfig.Method] = methodResult
+		}
+	}
+	// 幂等测试时为每个逻辑请求生成独立的幂等键
+	if request.IdempotencyKey != "" {
+		reqConfig.Headers = maps.Clone(reqConfig.Headers)
+		if reqConfig.Headers == nil {
+			reqConfig.Headers = make(map[string]string)
+		}
+		reqConfig.Headers[request.IdempotencyKey] = newUUID()
+	}
+	renderTime := time.Since(renderStart)
+	timing := &RequestTiming{}
+	ctx, stopChaos, chaos := newChaosContext()
+	reqStartTime := time.Now()
+	c.beginRequest()
+	// 使用请求处理器构建请求
+	resp, _, err := handler.NewRequest(ctx, reqConfig, timing)
+	// 响应状态码在 RetryOn 中时按指数退避重试,耗时包含重试等待时间
+	for attempt := 0; err == nil && attempt < request.maxRetries() && slices.Contains(request.RetryOn, resp.StatusCode); attempt++ {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		// 被中断或到达截止时间时不再等待重试,该请求不计入结果
+		select {
+		case <-time.After(retryBackoff << attempt):
+		case <-c.ctx.Done():
+			c.endRequest()
+			stopChaos()
+			return
+		}
+		w.result.RetryCount++
+		resp, _, err = handler.NewRequest(ctx, reqConfig, timing)
+	}
+	c.endRequest()
+	w.result.TotalRequests += 1
+	metrics.addRequest()
+	if methodResult != nil {
+		methodResult.TotalRequests++
+	}
+	w.clientOverhead += renderTime + timing.Prepare
+	if timing.BodySize > 0 {
+		w.result.compressedRequests++
+		w.result.requestBodyBytes += timing.BodySize
+		w.result.compressedBytes += timing.CompressedBodySize
+	}
+	// WebSocket请求的耗时为消息往返耗时,不包含建立连接的握手耗时
+	w.result.HandshakeStat.add(timing.Handshake)
+	reqStartTime = reqStartTime.Add(timing.Handshake)
+	if traceRequest {
+		phases := timing.Phases()
+		w.result.DNSStat.add(phases.DNS)
+		w.result.ConnectStat.add(phases.Connect)
+		w.result.TLSStat.add(phases.TLS)
+	}
+	progress <- struct{}{}
+
+	if err != nil {
+		stopChaos()
+		sampleRecorder.Record(c.index, reqStartTime, 0, err)
+		// 判断超时
+		if chaos && errors.Is(err, context.Canceled) {
+			w.result.ClientAborted++
+		} else if timing.ConnectErr() != nil {
+			// 连接建立失败单独统计,与连接建立后的超时区分
+			w.result.ConnectFailures++
+			w.result.ErrorCategories[classifyNetworkError(err)]++
+			w.result.ErrorMessages[err.Error()]++
+		} else if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			elapsed := time.Since(reqStartTime).Milliseconds() // 请求耗时,单位:毫秒
+			w.result.RequestTimeoutNum++
+			w.result.RequestsTimes = append(w.result.RequestsTimes, elapsed)
+			w.result.slowest.add(SlowRequest{Method: cmp.Or(reqConfig.Method, "GET"), URL: reqConfig.URL, ElapsedMs: elapsed})
+			metrics.observe(elapsed)
+			if timeSeries {
+				second := int64(time.Since(totalStartTime) / time.Second)
+				w.secondTimes[second] = append(w.secondTimes[second], elapsed)
+			}
+		} else {
+			w.result.NetworkErrors++
+			w.result.ErrorCategories[classifyNetworkError(err)]++
+			w.result.ErrorMessages[err.Error()]++
+		}
+
+	} else {
+		// 确保响应体被读取和关闭,io.Discard 丢弃响应体内容
+		// io.Copy(io.Discard, resp.Body)
+
+		// 读取并打印内容
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		stopChaos()
+		sampleRecorder.Record(c.index, reqStartTime, resp.StatusCode, err)
+		if chaos && errors.Is(err, context.Canceled) {
+			w.result.ClientAborted++
+			return
+		}
+		elapsed := time.Since(reqStartTime).Milliseconds() // 请求耗时,单位:毫秒
+		w.result.RequestsTimes = append(w.result.RequestsTimes, elapsed)
+		w.result.TotalBytes += int64(len(body))
+		w.result.slowest.add(SlowRequest{Method: cmp.Or(reqConfig.Method, "GET"), URL: reqConfig.URL, ElapsedMs: elapsed, Status: resp.StatusCode})
+		metrics.observe(elapsed)
+		if timeSeries {
+			second := int64(time.Since(totalStartTime) / time.Second)
+			w.secondTimes[second] = append(w.secondTimes[second], elapsed)
+		}
+		if traceRequest && !timing.FirstByte.IsZero() {
+			w.result.TTFBTimes = append(w.result.TTFBTimes, timing.FirstByte.Sub(reqStartTime).Milliseconds())
+		}
+
+		if err != nil {
+			w.result.NetworkErrors++
+			w.result.ErrorCategories[classifyNetworkError(err)]++
+			w.result.ErrorMessages[fmt.Sprintf(tr("read_body_error"), err)]++
+			return
+		}
+		harRecorder.Record(resp, body, reqStartTime, timing)
+
+		if debug {
+			fmt.Printf(tr("response_body"), string(body))
+		}
+		var statusFlag = false
+		// 数据文件的 expected_status 列会覆盖本次请求的期望状态码
+		if reqConfig.Response.Status.matches(resp.StatusCode) {
+			statusFlag = true
+		} else {
+			statusFlag = false
+		}
+		var checks responseChecks
+		if request.Response.Data != nil {
+			var jsonStr = string(body)
+			for key, value := range request.Response.Data {
+				if pattern, ok := request.Response.patterns[key]; ok {
+					jsonResult := gjson.Get(jsonStr, key)
+					if !jsonResult.Exists() || !pattern.MatchString(jsonResult.String()) {
+						checks.fail(fmt.Sprintf(tr("field_regex_mismatch"), key, pattern, jsonResult.Value()))
 					} else {
-						statusFlag = false
+						checks.pass()
 					}
-					var fieldFlag = true
-					if request.Response.Data != nil {
-						var jsonStr = string(body)
-						for key, value := range request.Response.Data {
-							jsonValue := gjson.Get(jsonStr, key).Value()
-							if jsonValue != value {
-								mu.Lock()
-								fieldFlag = false
-								result.ErrorMessages[fmt.Sprintf("字段 %v 验证错误, 期望: %v, 实际: %v", key, value, jsonValue)]++
-								mu.Unlock()
-							}
-						}
+					continue
+				}
+				if operator, ok := request.Response.operators[key]; ok {
+					jsonResult := gjson.Get(jsonStr, key)
+					if !operator.Check(jsonResult) {
+						checks.fail(fmt.Sprintf(tr("field_operator_mismatch"), key, operator, jsonResult.Value()))
+					} else {
+						checks.pass()
 					}
-					// fmt.Printf("statusFlag:%v,fieldFlag:%v\n", statusFlag, fieldFlag)
-					if statusFlag && fieldFlag {
-						// elapsed := time.Since(reqStartTime).Milliseconds() // 请求耗时,单位:毫秒
-						mu.Lock()
-						result.SuccessRequests += 1
-						mu.Unlock()
+					continue
+				}
+				if expectedRange, ok := parseFieldRange(value); ok {
+					jsonResult := gjson.Get(jsonStr, key)
+					if !expectedRange.Contains(jsonResult) {
+						checks.fail(fmt.Sprintf(tr("field_out_of_range"), key, expectedRange, jsonResult.Value()))
 					} else {
-						if !statusFlag {
-							mu.Lock()
-							result.ErrorCodes[resp.StatusCode]++
-							mu.Unlock()
-						}
+						checks.pass()
 					}
-
+					continue
 				}
+				jsonResult := gjson.Get(jsonStr, key)
+				if !fieldEqual(value, jsonResult) {
+					checks.fail(fmt.Sprintf(tr("field_mismatch"), key, value, jsonResult.Value()))
+				} else {
+					checks.pass()
+				}
+			}
+		}
+		for key, expectedType := range request.Response.Types {
+			actualType := jsonTypeName(gjson.Get(string(body), key))
+			if actualType != expectedType {
+				checks.fail(fmt.Sprintf(tr("field_type_mismatch"), key, expectedType, actualType))
+			} else {
+				checks.pass()
+			}
+		}
+		for name, expectedHeader := range request.Response.Headers {
+			if actualHeader := resp.Header.Get(name); actualHeader != expectedHeader {
+				checks.fail(fmt.Sprintf(tr("header_mismatch"), name, expectedHeader, actualHeader))
+			} else {
+				checks.pass()
+			}
+		}
+		if request.Response.Body != "" {
+			if string(body) != request.Response.Body {
+				checks.fail(fmt.Sprintf(tr("body_mismatch"), request.Response.Body, string(body)))
+			} else {
+				checks.pass()
+			}
+		}
+		if len(request.Response.Cookies) > 0 {
+			failures := checkCookies(request.Response.Cookies, resp.Cookies())
+			for _, failure := range failures {
+				checks.fail(failure)
+			}
+			checks.passed += len(request.Response.Cookies) - len(failures)
+		}
+		if request.Response.Assert != nil {
+			if !request.Response.Assert.Eval(string(body)) {
+				checks.fail(fmt.Sprintf(tr("assert_failed"), request.Response.Assert))
+			} else {
+				checks.pass()
+			}
+		}
+		if request.Response.schema != nil {
+			if err := validateSchema(request.Response.schema, body); err != nil {
+				checks.fail(fmt.Sprintf(tr("schema_failed"), err))
+			} else {
+				checks.pass()
+			}
+		}
+		if captureShape && statusFlag {
+			if w.result.Shape == nil {
+				w.result.Shape = jsonShape(string(body))
+			}
+		}
+		if request.Response.Shape != nil {
+			added, removed := diffShape(request.Response.Shape, jsonShape(string(body)))
+			if len(added) > 0 || len(removed) > 0 {
+				checks.fail(fmt.Sprintf(tr("shape_mismatch"), added, removed))
+			} else {
+				checks.pass()
+			}
+		}
+		if request.Response.Monotonic != "" {
+			monoValue := gjson.Get(string(body), request.Response.Monotonic)
+			if monoValue.Type != gjson.Number {
+				checks.fail(fmt.Sprintf(tr("monotonic_not_number"), request.Response.Monotonic, monoValue.Value()))
+			} else {
+				current := monoValue.Float()
+				if w.hasLastMonotonic && current < w.lastMonotonic {
+					w.result.MonotonicViolations++
+					checks.fail(fmt.Sprintf(tr("monotonic_decreased"), request.Response.Monotonic))
+				} else {
+					checks.pass()
+				}
+				w.lastMonotonic = current
+				w.hasLastMonotonic = true
+			}
+		}
+		// fmt.Printf("statusFlag:%v,checks:%+v\n", statusFlag, checks)
+		if request.Response.matched(statusFlag, checks) {
+			// elapsed := time.Since(reqStartTime).Milliseconds() // 请求耗时,单位:毫秒
+			w.result.SuccessRequests += 1
+			metrics.addSuccess()
+			if methodResult != nil {
+				methodResult.SuccessRequests++
+			}
+		} else {
+			for _, failure := range checks.failures {
+				w.result.ErrorMessages[failure]++
+			}
+			// 状态码错误优先计入错误状态码,状态码正确时计入校验失败
+			if !statusFlag {
+				w.result.ErrorCodes[resp.StatusCode]++
+				w.result.addErrorBody(resp.StatusCode, body)
+			} else {
+				w.result.ValidationFailures++
+			}
+		}
 
+		// 使用相同的幂等键再次发送,响应必须与首次一致
+		if request.IdempotencyKey != "" {
+			if violation := checkIdempotentReplay(runCtx, handler, reqConfig, resp.StatusCode, body); violation != "" {
+				w.result.IdempotencyViolations++
+				w.result.ErrorMessages[violation]++
 			}
-		}()
+		}
+
+	}
+}
+
+// 保存每个请求配置的响应结构,按配置顺序保存为二维数组
+func saveShapes(filePath string, results []Result) error {
+	shapes := make([][]string, len(results))
+	for index, reqResult := range results {
+		shapes[index] = reqResult.Shape
+	}
+	data, err := json.MarshalIndent(shapes, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filePath, data)
+}
+
+// 加载基准响应结构,按配置顺序设置到每个请求配置的 Response.Shape
+func loadShapes(filePath string, requestList []RequestConfig) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	var shapes [][]string
+	if err := json.Unmarshal(data, &shapes); err != nil {
+		return err
+	}
+	for index := range requestList {
+		if index < len(shapes) && shapes[index] != nil {
+			requestList[index].Response.Shape = shapes[index]
+		}
 	}
+	return nil
+}
 
+// 使用相同的请求配置(含幂等键)重复发送请求,与首次响应的状态码和响应体对比,不一致时返回原因
+// 重放请求因 ctx 被取消(如 Ctrl-C)而失败时不视为不一致
+func checkIdempotentReplay(ctx context.Context, handler *RequestHandler, reqConfig RequestConfig, status int, body []byte) string {
+	resp, _, err := handler.NewRequest(ctx, reqConfig, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ""
+		}
+		return fmt.Sprintf(tr("idempotency_replay_error"), err)
+	}
+	replayBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Sprintf(tr("idempotency_replay_error"), err)
+	}
+	if resp.StatusCode != status {
+		return fmt.Sprintf(tr("idempotency_status_mismatch"), status, resp.StatusCode)
+	}
+	if !bytes.Equal(replayBody, body) {
+		return tr("idempotency_body_mismatch")
+	}
+	return ""
+}
+
+// 使用最多 concurrency 个协程发送 total 个预热请求,只读取并丢弃响应,用于提前建立连接
+func runWarmup(ctx context.Context, handler *RequestHandler, limiter *rateLimiter, request RequestConfig, concurrency, total int64) {
+	var wg sync.WaitGroup
+	var remaining atomic.Int64
+	remaining.Store(total)
+	for range min(concurrency, total) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil && remaining.Add(-1) >= 0 {
+				if !limiter.Wait(ctx) {
+					return
+				}
+				resp, _, err := handler.NewRequest(ctx, request.nextRequest(), nil)
+				if err != nil {
+					continue
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+		}()
+	}
 	wg.Wait()
-	bar.Finish()
-	result.TotalTime = time.Since(totalStartTime).Milliseconds()
-	result.AvgTime = average(result.RequestsTimes)
-	result.MaxTime = maxDuration(result.RequestsTimes)
+}
 
-	return result
+// 冒烟检查: 发送单个请求,状态码与期望一致时通过,失败时按 -smoke-retries 和 -smoke-interval 重试,ctx 结束时停止重试
+func runSmokeCheck(ctx context.Context, handler *RequestHandler, request RequestConfig) error {
+	var err error
+	for attempt := 0; attempt <= smokeRetries; attempt++ {
+		if attempt > 0 {
+			if !quiet {
+				fmt.Printf(tr("smoke_retry"), attempt, smokeRetries, err)
+			}
+			select {
+			case <-time.After(smokeInterval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		var resp *http.Response
+		reqConfig := request.nextRequest()
+		resp, _, err = handler.NewRequest(ctx, reqConfig, nil)
+		if err != nil {
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if reqConfig.Response.Status.matches(resp.StatusCode) {
+			return nil
+		}
+		err = fmt.Errorf(tr("smoke_status_mismatch"), reqConfig.Response.Status, resp.StatusCode)
+	}
+	return err
+}
+
+// 按 -client-chaos 比例随机选中请求,选中的请求在随机延迟后取消,模拟客户端提前断开
+// 请求结束后需要调用返回的 stop 释放资源,chaos 表示该请求是否被选中
+func newChaosContext() (ctx context.Context, stop func(), chaos bool) {
+	if clientChaos <= 0 || rand.Float64() >= clientChaos {
+		return context.Background(), func() {}, false
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	timer := time.AfterFunc(rand.N(clientChaosMaxDelay), cancel)
+	return ctx, func() {
+		timer.Stop()
+		cancel()
+	}, true
+}
+
+// 根据每秒的请求耗时生成时间序列,按秒排序,没有请求完成的秒也会输出
+func buildTimeSeries(secondTimes map[int64][]int64) []SecondStat {
+	if len(secondTimes) == 0 {
+		return nil
+	}
+	lastSecond := slices.Max(slices.Collect(maps.Keys(secondTimes)))
+	series := make([]SecondStat, 0, lastSecond+1)
+	for second := int64(0); second <= lastSecond; second++ {
+		durations := secondTimes[second]
+		series = append(series, SecondStat{
+			Second:   second,
+			Requests: int64(len(durations)),
+			P50Time:  percentile(durations, 50),
+			P95Time:  percentile(durations, 95),
+			MaxTime:  maxDuration(durations),
+		})
+	}
+	return series
+}
+
+// 启动进度条协程,工作协程通过返回的通道上报完成的请求,进度条只由该协程更新
+// 所有工作协程结束后调用返回的函数关闭通道并等待进度条完成
+// 进度条总数,按运行时长(duration 大于0)运行时请求数未知,进度条只显示已完成的请求数
+func progressTotal(totalRequests int64, duration time.Duration) int64 {
+	if duration > 0 {
+		return 0
+	}
+	return totalRequests
+}
+
+func startProgress(total int64) (chan<- struct{}, func()) {
+	// 同时运行多个配置时使用共用的总进度条,由 runParallelTest 负责关闭
+	if sharedProgress != nil {
+		return sharedProgress, func() {}
+	}
+	bar := pb.New64(total)
+	if quiet || liveReport {
+		bar.SetWriter(io.Discard)
+	}
+	bar.Start()
+
+	progress := make(chan struct{}, 1024)
+	done := make(chan struct{})
+	go func() {
+		for range progress {
+			bar.Increment()
+		}
+		bar.Finish()
+		close(done)
+	}()
+	return progress, func() {
+		close(progress)
+		<-done
+	}
+}
+
+// 检查单个请求配置的结果是否通过,返回未通过的原因
+// 配置了 -max-error-rate 时失败请求只按失败率阈值判断,与退出状态码的判断一致,否则存在失败请求即未通过
+func checkResult(reqResult Result) []string {
+	var failures []string
+	if reqResult.SmokeError != "" {
+		failures = append(failures, fmt.Sprintf(tr("check_smoke_failed"), reqResult.SmokeError))
+	}
+	if failed := reqResult.TotalRequests - reqResult.SuccessRequests; failed > 0 && maxErrorRate < 0 {
+		failures = append(failures, fmt.Sprintf(tr("check_failed_requests"), failed, reqResult.TotalRequests))
+	}
+	return append(failures, checkThresholds(reqResult)...)
+}
+
+// 检查单个请求配置的结果是否超出阈值,返回超出的阈值,存在超出阈值的配置时程序以非0状态码退出
+func checkThresholds(reqResult Result) []string {
+	var violations []string
+	minQPS := reqResult.RequestConfig.Response.MinQPS
+	if minQPS <= 0 {
+		minQPS = defaultMinQPS
+	}
+	if okQPS := qps(reqResult.SuccessRequests, reqResult.TotalTime); minQPS > 0 && okQPS < minQPS {
+		violations = append(violations, fmt.Sprintf(tr("threshold_min_qps"), okQPS, minQPS))
+	}
+	if errorRate := ratioPercent(reqResult.TotalRequests-reqResult.SuccessRequests, reqResult.TotalRequests); maxErrorRate >= 0 && errorRate > maxErrorRate {
+		violations = append(violations, fmt.Sprintf(tr("threshold_max_error_rate"), errorRate, maxErrorRate))
+	}
+	if p95 := time.Duration(reqResult.P95Time) * time.Millisecond; maxP95 > 0 && p95 > maxP95 {
+		violations = append(violations, fmt.Sprintf(tr("threshold_max_p95"), p95, maxP95))
+	}
+	return violations
+}
+
+// 打印所有超出阈值的配置,返回是否存在超出阈值的配置
+func reportThresholds(results []Result) bool {
+	violated := false
+	for index, reqResult := range results {
+		for _, violation := range checkThresholds(reqResult) {
+			violated = true
+			fmt.Printf(tr("threshold_violation"), index+1, reqResult.RequestConfig.Method, reqResult.RequestConfig.URL, violation)
+		}
+	}
+	return violated
 }
 
 // 显示测试结果
 func showResult(results []Result) {
-	jsonByte, _ := json.MarshalIndent(results, "", "    ")
-	writeFile("./result."+configFileName, jsonByte)
+	if slices.Contains(outputFormats, "json") {
+		jsonByte, _ := json.MarshalIndent(results, "", "    ")
+		writeFile("./result."+configFileName, jsonByte)
+	}
+	if slices.Contains(outputFormats, "csv") {
+		csvFile := "./result." + strings.TrimSuffix(configFileName, filepath.Ext(configFileName)) + ".csv"
+		if err := writeResultCSV(csvFile, results); err != nil {
+			fmt.Printf(tr("write_file_failed"), csvFile, err)
+		}
+	}
+	if slices.Contains(outputFormats, "html") {
+		htmlFile := "./result." + strings.TrimSuffix(configFileName, filepath.Ext(configFileName)) + ".html"
+		if err := writeResultHTML(htmlFile, results); err != nil {
+			fmt.Printf(tr("write_file_failed"), htmlFile, err)
+		}
+	}
 
 	// 显示每个请求配置的单独结果
 	for index, reqResult := range results {
 		if debug {
-			fmt.Printf("请求结果: %#v \n", reqResult)
+			fmt.Printf(tr("result_debug"), reqResult)
+		}
+		fmt.Printf(tr("result_title"), index+1)
+		if reqResult.ConfigFile != "" {
+			fmt.Printf(tr("result_config_file"), reqResult.ConfigFile)
 		}
-		fmt.Printf("====== 请求配置 #%d ======\n", index+1)
 		fmt.Printf("【URL】:[%s] %s\n", reqResult.RequestConfig.Method, reqResult.RequestConfig.URL)
-		fmt.Printf("【All-QPS】:%.2f\n\n", float64(reqResult.TotalRequests)/float64(reqResult.TotalTime)*1000)
-		fmt.Printf("【 OK-QPS】:%.2f\n\n", float64(reqResult.SuccessRequests)/float64(reqResult.TotalTime)*1000)
+		fmt.Printf("【All-QPS】:%.2f\n\n", qps(reqResult.TotalRequests, reqResult.TotalTime))
+		fmt.Printf("【 OK-QPS】:%.2f\n\n", qps(reqResult.SuccessRequests, reqResult.TotalTime))
 
-		fmt.Printf("总请求: %d, 成功数: %d, 失败数: %d, 其中超时 %d, 成功率: %.2f%%\n", reqResult.TotalRequests, reqResult.SuccessRequests, reqResult.TotalRequests-reqResult.SuccessRequests, reqResult.RequestTimeoutNum, float64(reqResult.SuccessRequests)/float64(reqResult.TotalRequests)*100)
-		fmt.Printf("总耗时: %v, 最大耗时: %v, 平均耗时: %v \n", MsToSeconds(reqResult.TotalTime), MsToSeconds(reqResult.MaxTime), MsToSeconds(reqResult.AvgTime))
+		if reqResult.SmokeError != "" {
+			fmt.Printf(tr("smoke_failed"), reqResult.SmokeError)
+		}
+		fmt.Printf(tr("result_summary"), reqResult.TotalRequests, reqResult.SuccessRequests, reqResult.TotalRequests-reqResult.SuccessRequests, reqResult.RequestTimeoutNum, ratioPercent(reqResult.SuccessRequests, reqResult.TotalRequests))
+		clientErrors, serverErrors := sumErrorCodeClasses(reqResult.ErrorCodes)
+		fmt.Printf(tr("failure_breakdown"), sumErrorCodes(reqResult.ErrorCodes), clientErrors, serverErrors, reqResult.ValidationFailures, reqResult.RequestTimeoutNum, reqResult.ConnectFailures, reqResult.NetworkErrors, reqResult.ClientAborted)
+		fmt.Printf(tr("result_time"), MsToSeconds(reqResult.TotalTime), MsToSeconds(reqResult.MaxTime), MsToSeconds(reqResult.MinTime), MsToSeconds(reqResult.AvgTime), MsToSeconds(reqResult.StdDevTime))
+		fmt.Printf(tr("result_percentiles"), MsToSeconds(reqResult.P50Time), MsToSeconds(reqResult.P90Time), MsToSeconds(reqResult.P95Time), MsToSeconds(reqResult.P99Time))
 
+		fmt.Printf(tr("client_overhead"), reqResult.AvgClientOverheadUs)
+		fmt.Printf(tr("max_in_flight"), reqResult.MaxInFlight)
+		if traceRequest {
+			fmt.Printf(tr("conn_phases"), reqResult.DNSStat.AvgUs, reqResult.DNSStat.Count, reqResult.ConnectStat.AvgUs, reqResult.ConnectStat.Count, reqResult.TLSStat.AvgUs, reqResult.TLSStat.Count)
+		}
+		if reqResult.HandshakeStat.Count > 0 {
+			fmt.Printf(tr("ws_handshake"), reqResult.HandshakeStat.AvgUs, reqResult.HandshakeStat.Count)
+		}
+		if len(reqResult.TTFBTimes) > 0 {
+			fmt.Printf(tr("ttfb_time"), MsToSeconds(reqResult.AvgTTFBTime), MsToSeconds(reqResult.P50TTFBTime), MsToSeconds(reqResult.P95TTFBTime), MsToSeconds(reqResult.P99TTFBTime), MsToSeconds(reqResult.MaxTTFBTime), MsToSeconds(max(reqResult.AvgTime-reqResult.AvgTTFBTime, 0)))
+		}
+		if ratio := zeroRatio(reqResult.RequestsTimes); ratio > zeroLatencyWarnRatio {
+			fmt.Printf(tr("zero_latency_warning"), ratio*100)
+		}
+		// 超时的请求没有响应体,不计入平均响应大小
+		responses := int64(len(reqResult.RequestsTimes)) - reqResult.RequestTimeoutNum
+		if responses > 0 {
+			fmt.Printf(tr("response_size"), reqResult.TotalBytes/responses, reqResult.TotalBytes, throughputMBps(reqResult.TotalBytes, reqResult.TotalTime))
+		}
+		if reqResult.RequestBodySize > 0 {
+			fmt.Printf(tr("request_body_size"), reqResult.RequestBodySize, reqResult.CompressedBodySize)
+		}
+		if len(reqResult.MethodResults) > 0 {
+			fmt.Print(tr("method_results"))
+			for method, methodResult := range reqResult.MethodResults {
+				fmt.Printf(tr("method_result"), method, methodResult.TotalRequests, methodResult.SuccessRequests)
+			}
+		}
+		if reqResult.RetryCount > 0 {
+			fmt.Printf(tr("retry_count"), reqResult.RetryCount)
+		}
+		if reqResult.PriorityDelayed > 0 {
+			fmt.Printf(tr("priority_delayed"), reqResult.PriorityDelayed, ratioPercent(reqResult.PriorityDelayed, reqResult.TotalRequests))
+		}
+		if reqResult.IdempotencyViolations > 0 {
+			fmt.Printf(tr("idempotency_violations"), reqResult.IdempotencyViolations)
+		}
+		if reqResult.MonotonicViolations > 0 {
+			fmt.Printf(tr("monotonic_violations"), reqResult.RequestConfig.Response.Monotonic, reqResult.MonotonicViolations)
+		}
 		if len(reqResult.ErrorCodes) > 0 {
-			fmt.Println("错误状态码:")
+			fmt.Print(tr("error_codes"))
 			// fmt.Printf("错误码: %+v\n", reqResult.ErrorCodes)
 			for code, count := range reqResult.ErrorCodes {
-				fmt.Printf("[%d次] %d\n", count, code)
+				fmt.Printf(tr("count_prefix"), count, code)
+			}
+		}
+		if len(reqResult.ErrorCategories) > 0 {
+			fmt.Print(tr("error_categories"))
+			for _, category := range errorCategories {
+				if count := reqResult.ErrorCategories[category]; count > 0 {
+					fmt.Printf(tr("count_prefix"), count, tr("error_category_"+category))
+				}
 			}
 		}
 		if len(reqResult.ErrorMessages) > 0 {
-			fmt.Println("错误信息统计:")
+			fmt.Print(tr("error_messages"))
 			for msg, count := range reqResult.ErrorMessages {
-				fmt.Printf("[%d次] %s\n", count, msg)
+				fmt.Printf(tr("count_prefix"), count, msg)
+			}
+		}
+		if len(reqResult.ErrorBodies) > 0 {
+			fmt.Print(tr("error_bodies"))
+			for _, errorBody := range reqResult.ErrorBodies {
+				fmt.Printf(tr("error_body_row"), errorBody.Count, errorBody.Status, errorBody.Body)
+			}
+		}
+		if len(reqResult.Slowest) > 0 {
+			fmt.Printf(tr("slowest_title"), len(reqResult.Slowest))
+			for _, request := range reqResult.Slowest {
+				if request.Status == 0 {
+					fmt.Printf(tr("slowest_timeout"), MsToSeconds(request.ElapsedMs), request.Method, request.URL)
+				} else {
+					fmt.Printf(tr("slowest_row"), MsToSeconds(request.ElapsedMs), request.Method, request.URL, request.Status)
+				}
 			}
 		}
 		fmt.Printf("\n")
-		// 耗时分布统计
-		maxMs := reqResult.MaxTime
-		interval := int64(100)
-		maxInterval := maxMs/interval + 1
-		distribution := make([]int, maxInterval)
-
-		for _, d := range reqResult.RequestsTimes {
-			ms := d
-			index := ms / interval
-			if index >= maxInterval {
-				index = maxInterval - 1
+		if len(reqResult.TimeSeries) > 0 {
+			fmt.Print(tr("timeseries_title"))
+			for _, stat := range reqResult.TimeSeries {
+				fmt.Printf(tr("timeseries_row"), stat.Second, stat.Requests, MsToSeconds(stat.P50Time), MsToSeconds(stat.P95Time), MsToSeconds(stat.MaxTime))
 			}
-			distribution[index]++
+			fmt.Printf("\n")
 		}
+		// 耗时分布统计
+		interval := distributionInterval
+		distribution := latencyDistribution(reqResult.RequestsTimes, reqResult.MaxTime, interval)
+		maxInterval := int64(len(distribution))
 
 		// 打印耗时分布
-		fmt.Printf("每%dms耗时统计次数:\n", interval)
+		fmt.Printf(tr("distribution_title"), interval)
 		for i := int64(0); i < maxInterval; i++ {
 			start := i * interval
 			end := (i+1)*interval - 1
@@ -260,13 +1557,22 @@ func showResult(results []Result) {
 				continue
 			}
 			if i == maxInterval-1 {
-				fmt.Printf("%s+: %d次\n", MsToSeconds(start), distribution[i])
+				fmt.Printf(tr("distribution_last"), MsToSeconds(start), distribution[i])
 			} else {
-				fmt.Printf("%s-%s: %d次\n", MsToSeconds(start), MsToSeconds(end), distribution[i])
+				fmt.Printf(tr("distribution_range"), MsToSeconds(start), MsToSeconds(end), distribution[i])
 			}
 		}
 
 		// fmt.Printf("响应时间: %+v\n", reqResult.RequestsTimes)
 		fmt.Printf("\n")
 	}
+
+	// 所有请求配置的汇总
+	var totalRequests, successRequests int64
+	for _, reqResult := range results {
+		totalRequests += reqResult.TotalRequests
+		successRequests += reqResult.SuccessRequests
+	}
+	fmt.Print(tr("overall_title"))
+	fmt.Printf(tr("overall_summary"), len(results), totalRequests, successRequests, ratioPercent(successRequests, totalRequests), MsToSeconds(testElapsed.Milliseconds()))
 }
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 响应体读取到一半连接被关闭时,请求应计为失败并记录读取错误,而不是计为成功
+func TestSendCountsBodyReadErrorAsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		// 声明的 Content-Length 大于实际写入的响应体
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{\"partial\":")
+		buf.Flush()
+	}))
+	defer server.Close()
+
+	quiet = true
+	defer func() { quiet = false }()
+
+	const total = 10
+	result := runSingleConfigTest(0, RequestConfig{URL: server.URL, Method: "GET"}, 2, total, 5, 0)
+	if result.TotalRequests != total {
+		t.Fatalf("TotalRequests = %d, want %d", result.TotalRequests, total)
+	}
+	if result.SuccessRequests != 0 {
+		t.Errorf("SuccessRequests = %d, want 0", result.SuccessRequests)
+	}
+	if result.NetworkErrors != total {
+		t.Errorf("NetworkErrors = %d, want %d", result.NetworkErrors, total)
+	}
+	message := fmt.Sprintf(tr("read_body_error"), io.ErrUnexpectedEOF)
+	if got := result.ErrorMessages[message]; got != total {
+		t.Errorf("ErrorMessages[%q] = %d, want %d; all messages: %v", message, got, total, result.ErrorMessages)
+	}
+}
+
+// 配置了 -max-error-rate 时失败率在阈值内的配置视为通过,与退出状态码的判断一致
+func TestCheckResultFollowsErrorRateThreshold(t *testing.T) {
+	defer func(rate float64) { maxErrorRate = rate }(maxErrorRate)
+	result := Result{TotalRequests: 100, SuccessRequests: 99, TotalTime: 1000}
+	tests := []struct {
+		name         string
+		maxErrorRate float64
+		wantFailures int
+	}{
+		{"no threshold", -1, 1},
+		{"within threshold", 5, 0},
+		{"exceeds threshold", 0.5, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxErrorRate = tt.maxErrorRate
+			if got := checkResult(result); len(got) != tt.wantFailures {
+				t.Errorf("checkResult() = %q, want %d failures", got, tt.wantFailures)
+			}
+			if violated := len(checkThresholds(result)) > 0; tt.maxErrorRate >= 0 && violated != (tt.wantFailures > 0) {
+				t.Errorf("checkThresholds() violated = %v, checkResult failures = %d", violated, tt.wantFailures)
+			}
+		})
+	}
+}
+
+// 请求名额: 按请求数运行时多个协程共领取恰好 totalRequests 个名额,按运行时长运行时在截止时间后不再发放
+func TestRequestQuota(t *testing.T) {
+	tests := []struct {
+		name          string
+		totalRequests int64
+		duration      time.Duration
+		wantCount     int64
+	}{
+		{"count", 1000, 0, 1000},
+		{"single", 1, 0, 1},
+		{"zero", 0, 0, 0},
+		{"duration ignores count", 1, 50 * time.Millisecond, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota, cancel := newRequestQuota(tt.totalRequests, tt.duration)
+			defer cancel()
+			start := time.Now()
+			var count atomic.Int64
+			var wg sync.WaitGroup
+			for range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for quota.next() {
+						count.Add(1)
+						if tt.duration > 0 {
+							time.Sleep(time.Millisecond)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			if !quota.exhausted() {
+				t.Error("exhausted() = false after next() returned false")
+			}
+			if tt.wantCount >= 0 && count.Load() != tt.wantCount {
+				t.Errorf("handed out %d, want %d", count.Load(), tt.wantCount)
+			}
+			if tt.duration > 0 {
+				if elapsed := time.Since(start); elapsed < tt.duration || elapsed > tt.duration+time.Second {
+					t.Errorf("stopped after %v, want about %v", elapsed, tt.duration)
+				}
+				if count.Load() <= tt.totalRequests {
+					t.Errorf("handed out %d, want more than -n %d", count.Load(), tt.totalRequests)
+				}
+			}
+		})
+	}
+
+	// 中断后不再发放名额
+	defer func(ctx context.Context) { runCtx = ctx }(runCtx)
+	ctx, stop := context.WithCancel(context.Background())
+	runCtx = ctx
+	quota, cancel := newRequestQuota(10, 0)
+	defer cancel()
+	if !quota.next() {
+		t.Fatal("next() = false before interrupt")
+	}
+	stop()
+	if quota.next() || !quota.exhausted() {
+		t.Error("quota still hands out requests after interrupt")
+	}
+}
+
+// 启动工作协程: 未配置 -rampup 时立即启动全部协程,配置时按间隔逐个启动,名额用完或到截止时间后不再启动
+func TestStartWorkers(t *testing.T) {
+	defer func(d time.Duration) { rampUp = d }(rampUp)
+	tests := []struct {
+		name          string
+		concurrency   int64
+		rampUp        time.Duration
+		totalRequests int64
+		duration      time.Duration
+		wantWorkers   int
+		wantInterval  time.Duration
+	}{
+		{"no rampup", 4, 0, 100, 0, 4, 0},
+		{"rampup staggers workers", 4, 200 * time.Millisecond, 1000, 0, 4, 50 * time.Millisecond},
+		{"stops when requests run out", 4, 400 * time.Millisecond, 2, 0, 1, 0},
+		{"stops at deadline", 4, time.Second, 0, 100 * time.Millisecond, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rampUp = tt.rampUp
+			quota, cancel := newRequestQuota(tt.totalRequests, tt.duration)
+			defer cancel()
+			var mu sync.Mutex
+			var starts []time.Time
+			var wg sync.WaitGroup
+			startWorkers(&wg, quota, tt.concurrency, func() {
+				mu.Lock()
+				starts = append(starts, time.Now())
+				mu.Unlock()
+				for quota.next() {
+					time.Sleep(time.Millisecond)
+				}
+			})
+			wg.Wait()
+			if len(starts) != tt.wantWorkers {
+				t.Fatalf("started %d workers, want %d", len(starts), tt.wantWorkers)
+			}
+			if tt.wantInterval > 0 {
+				slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+				for i := 1; i < len(starts); i++ {
+					// 允许少量计时误差
+					if gap := starts[i].Sub(starts[i-1]); gap < tt.wantInterval*9/10 {
+						t.Errorf("worker %d started %v after the previous one, want at least %v", i, gap, tt.wantInterval)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 实时指标,通过 -metrics-addr 启用,为 nil 时不记录
+var liveMetrics *metricsCollector
+
+// 耗时直方图的区间上限,单位:秒,与 Prometheus 客户端的默认区间一致
+var metricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// 按请求配置(URL和请求方法)汇总的实时指标
+type metricsCollector struct {
+	mu      sync.Mutex
+	configs []*configMetrics
+}
+
+// 单个请求配置的实时指标,工作协程通过原子操作更新
+type configMetrics struct {
+	url      string
+	method   string
+	requests atomic.Int64
+	success  atomic.Int64
+	// 每个区间的请求数(非累计),最后一个为 +Inf
+	buckets []atomic.Int64
+	sumMs   atomic.Int64
+	count   atomic.Int64
+}
+
+// 获取请求配置对应的指标,相同URL和请求方法的配置共用一组指标,collector 为 nil 时返回 nil
+func (c *metricsCollector) forConfig(request RequestConfig) *configMetrics {
+	if c == nil {
+		return nil
+	}
+	method := cmp.Or(request.Method, "GET")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, metrics := range c.configs {
+		if metrics.url == request.URL && metrics.method == method {
+			return metrics
+		}
+	}
+	metrics := &configMetrics{
+		url:     request.URL,
+		method:  method,
+		buckets: make([]atomic.Int64, len(metricsBuckets)+1),
+	}
+	c.configs = append(c.configs, metrics)
+	return metrics
+}
+
+// 记录一次请求
+func (m *configMetrics) addRequest() {
+	if m != nil {
+		m.requests.Add(1)
+	}
+}
+
+// 记录一次成功请求
+func (m *configMetrics) addSuccess() {
+	if m != nil {
+		m.success.Add(1)
+	}
+}
+
+// 记录一次请求耗时,单位:毫秒
+func (m *configMetrics) observe(elapsedMs int64) {
+	if m == nil {
+		return
+	}
+	seconds := float64(elapsedMs) / 1000
+	index := len(metricsBuckets)
+	for i, upper := range metricsBuckets {
+		if seconds <= upper {
+			index = i
+			break
+		}
+	}
+	m.buckets[index].Add(1)
+	m.sumMs.Add(elapsedMs)
+	m.count.Add(1)
+}
+
+// 按 Prometheus 文本格式输出所有指标
+func (c *metricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	configs := append([]*configMetrics(nil), c.configs...)
+	c.mu.Unlock()
+
+	var buf strings.Builder
+	buf.WriteString("# HELP gotest_requests_total Total number of requests sent.\n")
+	buf.WriteString("# TYPE gotest_requests_total counter\n")
+	for _, m := range configs {
+		fmt.Fprintf(&buf, "gotest_requests_total{%s} %d\n", m.labels(), m.requests.Load())
+	}
+	buf.WriteString("# HELP gotest_success_total Total number of successful requests.\n")
+	buf.WriteString("# TYPE gotest_success_total counter\n")
+	for _, m := range configs {
+		fmt.Fprintf(&buf, "gotest_success_total{%s} %d\n", m.labels(), m.success.Load())
+	}
+	buf.WriteString("# HELP gotest_request_duration_seconds Request latency in seconds.\n")
+	buf.WriteString("# TYPE gotest_request_duration_seconds histogram\n")
+	for _, m := range configs {
+		labels := m.labels()
+		var cumulative int64
+		for i, upper := range metricsBuckets {
+			cumulative += m.buckets[i].Load()
+			fmt.Fprintf(&buf, "gotest_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(upper, 'g', -1, 64), cumulative)
+		}
+		cumulative += m.buckets[len(metricsBuckets)].Load()
+		fmt.Fprintf(&buf, "gotest_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, cumulative)
+		fmt.Fprintf(&buf, "gotest_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(float64(m.sumMs.Load())/1000, 'f', -1, 64))
+		fmt.Fprintf(&buf, "gotest_request_duration_seconds_count{%s} %d\n", labels, m.count.Load())
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(buf.String()))
+}
+
+// 指标的标签
+func (m *configMetrics) labels() string {
+	return fmt.Sprintf("url=\"%s\",method=\"%s\"", escapeLabel(m.url), escapeLabel(m.method))
+}
+
+// 转义 Prometheus 标签值中的反斜杠、双引号和换行
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// 在 addr 上启动指标服务,路径为 /metrics,返回关闭服务的函数
+func startMetricsServer(addr string) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	liveMetrics = &metricsCollector{}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", liveMetrics)
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 混合模式: 所有请求配置共用一组工作协程,每个请求按 Weight 随机选择配置,模拟真实的流量组合
+// 并发数和总请求数为所有配置合计,结果仍按配置分别统计,每个配置的总耗时均为整个混合运行的耗时
+func runMixedTest(requestList []RequestConfig, concurrency, totalRequests, timeout int64) []Result {
+	results := make([]Result, len(requestList))
+	runs := make([]*configRun, len(requestList))
+	weights := make([]int, len(requestList))
+	for index, request := range requestList {
+		if !quiet {
+			fmt.Printf(tr("start_test"), index+1, request.Method, request.URL)
+		}
+		results[index] = newResult(request)
+		handler := newConfigHandler(request, timeout)
+		// 混合运行结束后关闭所有配置的处理器
+		defer handler.Close()
+		runs[index] = &configRun{index: index, request: request, handler: handler, metrics: liveMetrics.forConfig(request)}
+		weights[index] = request.weight()
+
+		// 冒烟检查失败的配置不参与混合运行
+		if smokeCheck {
+			if err := runSmokeCheck(runCtx, handler, request); err != nil {
+				results[index].SmokeError = err.Error()
+				weights[index] = 0
+				if !quiet {
+					fmt.Printf(tr("smoke_failed"), err)
+				}
+				continue
+			}
+		}
+		if warmupRequests > 0 {
+			runWarmup(runCtx, handler, nil, request, concurrency, warmupRequests)
+		}
+	}
+	if pickWeightedIndex(weights) < 0 {
+		return results
+	}
+
+	// 所有配置共用一个限速器,-rate 限制的是混合后的总请求速率
+	// 配置了 priority 时令牌不足时按优先级发放,否则按等待顺序发放
+	var limiter *rateLimiter
+	var prioLimiter *priorityLimiter
+	if slices.ContainsFunc(requestList, func(request RequestConfig) bool { return request.Priority != 0 }) {
+		prioLimiter = newPriorityLimiter(requestRate)
+		defer prioLimiter.Stop()
+	} else {
+		limiter = newRateLimiter(requestRate)
+		defer limiter.Stop()
+	}
+	quota, cancel := newRequestQuota(totalRequests, testDuration)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	clientOverheads := make([]time.Duration, len(requestList))
+	secondTimes := make([]map[int64][]int64, len(requestList))
+	for index := range secondTimes {
+		secondTimes[index] = make(map[int64][]int64)
+	}
+
+	progress, finishProgress := startProgress(progressTotal(totalRequests, testDuration))
+	totalStartTime := time.Now()
+	for _, run := range runs {
+		run.progress = progress
+		run.ctx = quota.ctx
+		run.startTime = totalStartTime
+	}
+	startWorkers(&wg, quota, concurrency, func() {
+		stats := make([]*workerStats, len(runs))
+		defer func() {
+			mu.Lock()
+			for index, workerStats := range stats {
+				if workerStats == nil {
+					continue
+				}
+				results[index].merge(&workerStats.result)
+				clientOverheads[index] += workerStats.clientOverhead
+				for second, times := range workerStats.secondTimes {
+					secondTimes[index][second] = append(secondTimes[index][second], times...)
+				}
+			}
+			mu.Unlock()
+		}()
+		for quota.next() {
+			var index int
+			var delayed bool
+			if prioLimiter != nil {
+				// 先选定配置,再按该配置的优先级等待令牌
+				index = pickWeightedIndex(weights)
+				var ok bool
+				if ok, delayed = prioLimiter.Wait(quota.ctx, runs[index].request.Priority); !ok {
+					break
+				}
+			} else {
+				if !limiter.Wait(quota.ctx) {
+					break
+				}
+				index = pickWeightedIndex(weights)
+			}
+			if stats[index] == nil {
+				stats[index] = newWorkerStats(runs[index].request)
+			}
+			if delayed {
+				stats[index].result.PriorityDelayed++
+			}
+			runs[index].send(stats[index])
+			if !quota.exhausted() {
+				thinkTime.wait(quota.ctx)
+			}
+		}
+	})
+
+	wg.Wait()
+	finishProgress()
+	totalTime := time.Since(totalStartTime)
+	for index := range results {
+		if results[index].SmokeError == "" {
+			results[index].finish(totalTime, clientOverheads[index], secondTimes[index])
+			results[index].MaxInFlight = runs[index].maxInFlight.Load()
+		}
+	}
+	return results
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// 网络错误分类,作为 Result.ErrorCategories 的key
+const (
+	errorCategoryDNS     = "dns"
+	errorCategoryRefused = "refused"
+	errorCategoryTLS     = "tls"
+	errorCategoryReset   = "reset"
+	errorCategoryOther   = "other"
+)
+
+// 输出网络错误分类时的顺序
+var errorCategories = []string{errorCategoryDNS, errorCategoryRefused, errorCategoryTLS, errorCategoryReset, errorCategoryOther}
+
+// 根据错误类型判断网络错误的分类: DNS解析失败、连接被拒绝、TLS错误、连接被重置或提前关闭、其他
+func classifyNetworkError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return errorCategoryDNS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return errorCategoryRefused
+	}
+	var (
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &alertErr) || errors.As(err, &verifyErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) ||
+		strings.Contains(err.Error(), "tls: ") {
+		return errorCategoryTLS
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errorCategoryReset
+	}
+	return errorCategoryOther
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// 网络错误分类: 按 http.Client 返回的包装后的错误判断分类
+func TestClassifyNetworkError(t *testing.T) {
+	// 模拟 http.Client 的错误包装: *url.Error -> *net.OpError -> *os.SyscallError
+	wrap := func(op string, err error) error {
+		return &url.Error{Op: "Get", URL: "http://example.com", Err: &net.OpError{Op: op, Net: "tcp", Err: err}}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"dns", wrap("dial", &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}), errorCategoryDNS},
+		{"refused", wrap("dial", os.NewSyscallError("connect", syscall.ECONNREFUSED)), errorCategoryRefused},
+		{"record header", wrap("read", tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}), errorCategoryTLS},
+		{"alert", wrap("remote error", tls.AlertError(40)), errorCategoryTLS},
+		{"unknown authority", wrap("dial", &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}), errorCategoryTLS},
+		{"hostname", wrap("dial", x509.HostnameError{Certificate: &x509.Certificate{}, Host: "example.com"}), errorCategoryTLS},
+		{"tls message", &url.Error{Op: "Get", URL: "https://example.com", Err: errors.New("tls: handshake failure")}, errorCategoryTLS},
+		{"reset", wrap("read", os.NewSyscallError("read", syscall.ECONNRESET)), errorCategoryReset},
+		{"broken pipe", wrap("write", os.NewSyscallError("write", syscall.EPIPE)), errorCategoryReset},
+		{"eof", &url.Error{Op: "Get", URL: "http://example.com", Err: io.EOF}, errorCategoryReset},
+		{"unexpected eof", fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), errorCategoryReset},
+		{"timeout", wrap("dial", os.NewSyscallError("connect", syscall.ETIMEDOUT)), errorCategoryOther},
+		{"plain", errors.New("something went wrong"), errorCategoryOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyNetworkError(tt.err); got != tt.want {
+				t.Errorf("classifyNetworkError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
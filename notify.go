@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// 运行结束后推送的汇总信息
+type notifySummary struct {
+	Config  string
+	Passed  bool
+	Results []notifyResult
+}
+
+// 单个请求配置的汇总信息
+type notifyResult struct {
+	Index       int
+	Method      string
+	URL         string
+	QPS         float64
+	P95Time     int64
+	SuccessRate float64
+	Passed      bool
+}
+
+// 默认的通知消息模板,输出markdown格式
+const defaultNotifyTemplate = `**{{.Config}}** {{if .Passed}}✅ PASS{{else}}❌ FAIL{{end}}
+{{range .Results}}- #{{.Index}} [{{.Method}}] {{.URL}}: QPS {{printf "%.2f" .QPS}}, p95 {{.P95Time}}ms, success {{printf "%.2f" .SuccessRate}}%{{if not .Passed}} ❌{{end}}
+{{end}}`
+
+// 将运行结果汇总后推送到webhook,消息内容使用 text/template 渲染,以 {"text": 消息} 格式发送
+func notifyWebhook(webhook, tmpl string, results []Result, timeout int64) error {
+	if tmpl == "" {
+		tmpl = defaultNotifyTemplate
+	}
+	t, err := template.New("notify").Parse(tmpl)
+	if err != nil {
+		return err
+	}
+
+	summary := notifySummary{Config: configFileName, Passed: true}
+	for index, reqResult := range results {
+		passed := len(checkResult(reqResult)) == 0
+		summary.Passed = summary.Passed && passed
+		summary.Results = append(summary.Results, notifyResult{
+			Index:       index + 1,
+			Method:      reqResult.RequestConfig.Method,
+			URL:         reqResult.RequestConfig.URL,
+			QPS:         qps(reqResult.TotalRequests, reqResult.TotalTime),
+			P95Time:     reqResult.P95Time,
+			SuccessRate: ratioPercent(reqResult.SuccessRequests, reqResult.TotalRequests),
+			Passed:      passed,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, summary); err != nil {
+		return err
+	}
+	return postWebhook(webhook, map[string]string{"text": buf.String()}, timeout)
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sort"
+	"strings"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+// 按配置顺序和原始大小写发送请求头的 RoundTripper
+// http.Header 会规范化请求头的大小写,发送时还会按字母顺序排序,
+// 该 RoundTripper 直接在连接上按 req.Proto 写入 HTTP/1.x 请求,每个请求使用独立的连接(Connection: close)
+type orderedHeaderTransport struct {
+	dialer net.Dialer
+	// https 请求使用的TLS配置,为 nil 时使用默认配置
+	tlsConfig *tls.Config
+	// 代理地址,为 nil 时与 net/http 一致使用环境变量 HTTP_PROXY、HTTPS_PROXY 中的代理设置
+	proxy *url.URL
+}
+
+// 请求上下文中保存有序请求头的key
+type orderedHeadersKey struct{}
+
+func (t *orderedHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ordered, _ := req.Context().Value(orderedHeadersKey{}).([][2]string)
+
+	conn, forwardProxy, err := t.dial(req)
+	if err != nil {
+		return nil, err
+	}
+	// 请求被取消或超时时关闭连接,中断阻塞中的读写
+	stop := context.AfterFunc(req.Context(), func() {
+		conn.Close()
+	})
+	closeConn := func() {
+		stop()
+		conn.Close()
+	}
+
+	if err := writeOrderedRequest(conn, req, ordered, forwardProxy); err != nil {
+		closeConn()
+		return nil, contextError(req.Context(), err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		closeConn()
+		return nil, contextError(req.Context(), err)
+	}
+	if trace := httptrace.ContextClientTrace(req.Context()); trace != nil && trace.GotFirstResponseByte != nil {
+		trace.GotFirstResponseByte()
+	}
+	resp.Body = &connBody{ReadCloser: resp.Body, close: closeConn}
+	return resp, nil
+}
+
+// 建立到目标地址的连接,https 时进行TLS握手
+// 使用代理时: socks5 代理和 https 请求经过代理建立到目标地址的隧道(https 通过 CONNECT),
+// http 代理的 http 请求直接发送给代理,此时返回该代理,请求行需要使用绝对URI
+func (t *orderedHeaderTransport) dial(req *http.Request) (net.Conn, *url.URL, error) {
+	ctx := req.Context()
+	host := req.URL.Hostname()
+	addr := hostPort(req.URL)
+	proxy := t.proxy
+	if proxy == nil {
+		var err error
+		if proxy, err = http.ProxyFromEnvironment(req); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.ConnectStart != nil {
+		trace.ConnectStart("tcp", addr)
+	}
+	var conn net.Conn
+	var forwardProxy *url.URL
+	var err error
+	switch {
+	case proxy == nil:
+		conn, err = bindDialer(t.dialer, addr).DialContext(ctx, "tcp", addr)
+	case proxy.Scheme == "socks5" || proxy.Scheme == "socks5h":
+		conn, err = t.dialSocks(ctx, proxy, addr)
+	default:
+		conn, err = t.dialProxy(ctx, proxy)
+		if err == nil && req.URL.Scheme == "https" {
+			if err = connectTunnel(ctx, conn, proxy, addr); err != nil {
+				conn.Close()
+				conn = nil
+			}
+		} else if err == nil {
+			forwardProxy = proxy
+		}
+	}
+	if trace != nil && trace.ConnectDone != nil {
+		trace.ConnectDone("tcp", addr, err)
+	}
+	if err != nil || req.URL.Scheme != "https" {
+		return conn, forwardProxy, err
+	}
+	tlsConn, err := t.handshake(ctx, conn, host, trace)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return tlsConn, nil, nil
+}
+
+// 在连接上进行TLS握手,trace 不为 nil 时记录握手耗时
+func (t *orderedHeaderTransport) handshake(ctx context.Context, conn net.Conn, serverName string, trace *httptrace.ClientTrace) (*tls.Conn, error) {
+	config := &tls.Config{}
+	if t.tlsConfig != nil {
+		config = t.tlsConfig.Clone()
+	}
+	config.ServerName = serverName
+	tlsConn := tls.Client(conn, config)
+	if trace != nil && trace.TLSHandshakeStart != nil {
+		trace.TLSHandshakeStart()
+	}
+	err := tlsConn.HandshakeContext(ctx)
+	if trace != nil && trace.TLSHandshakeDone != nil {
+		trace.TLSHandshakeDone(tlsConn.ConnectionState(), err)
+	}
+	return tlsConn, err
+}
+
+// 连接到 http 或 https 代理,https 代理时与代理进行TLS握手
+func (t *orderedHeaderTransport) dialProxy(ctx context.Context, proxy *url.URL) (net.Conn, error) {
+	addr := hostPort(proxy)
+	conn, err := bindDialer(t.dialer, addr).DialContext(ctx, "tcp", addr)
+	if err != nil || proxy.Scheme != "https" {
+		return conn, err
+	}
+	tlsConn, err := t.handshake(ctx, conn, proxy.Hostname(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// 通过 socks5 代理建立到目标地址的连接
+func (t *orderedHeaderTransport) dialSocks(ctx context.Context, proxy *url.URL, addr string) (net.Conn, error) {
+	dialer, err := xproxy.FromURL(proxy, bindDialer(t.dialer, proxy.Host))
+	if err != nil {
+		return nil, err
+	}
+	return dialer.(xproxy.ContextDialer).DialContext(ctx, "tcp", addr)
+}
+
+// 在代理连接上发送 CONNECT 请求建立到目标地址的隧道
+func connectTunnel(ctx context.Context, conn net.Conn, proxy *url.URL, addr string) error {
+	// 等待代理响应时请求被取消或超时,关闭连接中断读取
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n", addr, addr)
+	if auth := proxyAuthorization(proxy); auth != "" {
+		fmt.Fprintf(&buf, "Proxy-Authorization: %s\r\n", auth)
+	}
+	buf.WriteString("\r\n")
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		return contextError(ctx, err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return contextError(ctx, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf(tr("proxy_connect_failed"), resp.Status)
+	}
+	return nil
+}
+
+// 代理地址中包含用户名密码时返回 Basic 认证的 Proxy-Authorization 请求头,否则返回空字符串
+func proxyAuthorization(proxy *url.URL) string {
+	if proxy.User == nil {
+		return ""
+	}
+	password, _ := proxy.User.Password()
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(proxy.User.Username()+":"+password))
+}
+
+// 返回URL的 host:port,未指定端口时使用协议的默认端口
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// 按顺序写入请求行、请求头和请求体
+// 先写入有序请求头,未在其中出现的 Host、其他请求头(按字母排序)、Content-Length 和 Connection 依次追加在后面
+// forwardProxy 不为 nil 时请求发送给该 http 代理,请求行使用绝对URI并在最后追加 Proxy-Authorization
+func writeOrderedRequest(conn net.Conn, req *http.Request, ordered [][2]string, forwardProxy *url.URL) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	written := make(map[string]bool)
+	var buf bytes.Buffer
+	requestURI := req.URL.RequestURI()
+	if forwardProxy != nil {
+		requestURI = req.URL.String()
+	}
+	fmt.Fprintf(&buf, "%s %s %s\r\n", req.Method, requestURI, req.Proto)
+	hasHost := false
+	for _, header := range ordered {
+		if strings.EqualFold(header[0], "Host") {
+			hasHost = true
+		}
+	}
+	if !hasHost {
+		fmt.Fprintf(&buf, "Host: %s\r\n", req.Host)
+	}
+	for _, header := range ordered {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header[0], header[1])
+		written[http.CanonicalHeaderKey(header[0])] = true
+	}
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		if !written[http.CanonicalHeaderKey(key)] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range req.Header[key] {
+			// 与 net/http 一致,空的 User-Agent 表示不发送
+			if key == "User-Agent" && value == "" {
+				continue
+			}
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+		}
+	}
+	if len(body) > 0 && !written["Content-Length"] {
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(body))
+	}
+	if !written["Connection"] {
+		buf.WriteString("Connection: close\r\n")
+	}
+	if forwardProxy != nil {
+		if auth := proxyAuthorization(forwardProxy); auth != "" && !written["Proxy-Authorization"] {
+			fmt.Fprintf(&buf, "Proxy-Authorization: %s\r\n", auth)
+		}
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	_, err := conn.Write(buf.Bytes())
+	return err
+}
+
+// 请求被取消或超时时返回上下文的错误,便于调用方区分超时和网络错误
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// 读取完成后关闭底层连接的响应体
+type connBody struct {
+	io.ReadCloser
+	close func()
+}
+
+func (b *connBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.close()
+	return err
+}
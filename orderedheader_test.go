@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 记录收到的请求的测试代理,http 请求按绝对URI转发,CONNECT 请求建立隧道
+type testProxy struct {
+	mu       sync.Mutex
+	requests []string
+	auth     []string
+}
+
+func (p *testProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.requests = append(p.requests, r.Method+" "+r.RequestURI)
+	p.auth = append(p.auth, r.Header.Get("Proxy-Authorization"))
+	p.mu.Unlock()
+	if r.Method == http.MethodConnect {
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() {
+			io.Copy(target, buf)
+			target.Close()
+		}()
+		io.Copy(conn, target)
+		conn.Close()
+		return
+	}
+	r.Header.Del("Proxy-Authorization")
+	resp, err := http.DefaultTransport.RoundTrip(&http.Request{Method: r.Method, URL: r.URL, Header: r.Header, Body: r.Body, Host: r.Host})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// 有序请求头的请求配置了代理时同样经过代理: http 请求使用绝对URI,https 请求通过 CONNECT 建立隧道
+func TestOrderedHeaderTransportProxy(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+	httpsServer := httptest.NewTLSServer(handler)
+	defer httpsServer.Close()
+
+	for _, target := range []*httptest.Server{httpServer, httpsServer} {
+		proxy := &testProxy{}
+		proxyServer := httptest.NewServer(proxy)
+		proxyAddr, _ := url.Parse(proxyServer.URL)
+		proxyAddr.User = url.UserPassword("user", "pass")
+		client := &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: &orderedHeaderTransport{tlsConfig: &tls.Config{InsecureSkipVerify: true}, proxy: proxyAddr},
+		}
+		req, _ := http.NewRequest(http.MethodGet, target.URL+"/path?q=1", nil)
+		req = req.WithContext(context.WithValue(req.Context(), orderedHeadersKey{}, [][2]string{{"x-first", "1"}}))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", target.URL, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		proxyServer.Close()
+		if string(body) != "ok" {
+			t.Errorf("%s: body = %q, want ok", target.URL, body)
+		}
+
+		want := "GET " + target.URL + "/path?q=1"
+		if target == httpsServer {
+			want = "CONNECT " + target.Listener.Addr().String()
+		}
+		if len(proxy.requests) != 1 || proxy.requests[0] != want {
+			t.Errorf("%s: proxy requests = %q, want [%q]", target.URL, proxy.requests, want)
+		}
+		if len(proxy.auth) != 1 || proxy.auth[0] != proxyAuthorization(proxyAddr) {
+			t.Errorf("%s: Proxy-Authorization = %q, want %q", target.URL, proxy.auth, proxyAuthorization(proxyAddr))
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// 按优先级发放令牌的限速器,混合模式下配置了 priority 且使用 -rate 限速时使用
+// 令牌不足时等待中的请求按 priority 从高到低领取令牌,相同优先级按等待顺序领取
+type priorityLimiter struct {
+	limiter *rateLimiter
+	mu      sync.Mutex
+	waiters []*priorityWaiter
+	// 下一个等待者的序号
+	seq uint64
+	// 有新的等待者时通知发放协程
+	wake chan struct{}
+	done chan struct{}
+}
+
+// 等待令牌的请求
+type priorityWaiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{}
+	// 是否因更高优先级的请求先领取令牌而被推迟过
+	delayed bool
+}
+
+// 创建每秒发放 rate 个令牌的优先级限速器,rate 不大于0时返回 nil,表示不限速
+func newPriorityLimiter(rate int64) *priorityLimiter {
+	limiter := newRateLimiter(rate)
+	if limiter == nil {
+		return nil
+	}
+	l := &priorityLimiter{
+		limiter: limiter,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// 发放协程: 有等待者时每获得一个令牌就交给优先级最高的等待者
+func (l *priorityLimiter) run() {
+	for {
+		select {
+		case <-l.wake:
+		case <-l.done:
+			return
+		}
+		for {
+			select {
+			case <-l.limiter.ticker.C:
+			case <-l.done:
+				return
+			}
+			l.mu.Lock()
+			waiter := l.pop()
+			remaining := len(l.waiters)
+			l.mu.Unlock()
+			if waiter != nil {
+				close(waiter.ready)
+			}
+			if remaining == 0 {
+				break
+			}
+		}
+	}
+}
+
+// 取出优先级最高的等待者,优先级更低的其他等待者记为被推迟,调用方需持有锁
+func (l *priorityLimiter) pop() *priorityWaiter {
+	if len(l.waiters) == 0 {
+		return nil
+	}
+	best := 0
+	for i, waiter := range l.waiters {
+		if waiter.priority > l.waiters[best].priority ||
+			(waiter.priority == l.waiters[best].priority && waiter.seq < l.waiters[best].seq) {
+			best = i
+		}
+	}
+	chosen := l.waiters[best]
+	l.waiters = slices.Delete(l.waiters, best, best+1)
+	for _, waiter := range l.waiters {
+		if waiter.priority < chosen.priority {
+			waiter.delayed = true
+		}
+	}
+	return chosen
+}
+
+// 以 priority 优先级等待获取令牌,返回是否获取成功以及是否被更高优先级的请求推迟过,ctx 结束时返回 false
+func (l *priorityLimiter) Wait(ctx context.Context, priority int) (bool, bool) {
+	if ctx.Err() != nil {
+		return false, false
+	}
+	l.mu.Lock()
+	waiter := &priorityWaiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	l.seq++
+	l.waiters = append(l.waiters, waiter)
+	l.mu.Unlock()
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+	select {
+	case <-waiter.ready:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return true, waiter.delayed
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if index := slices.Index(l.waiters, waiter); index >= 0 {
+			l.waiters = slices.Delete(l.waiters, index, index+1)
+		}
+		return false, false
+	}
+}
+
+// 停止发放协程和限速器
+func (l *priorityLimiter) Stop() {
+	if l != nil {
+		close(l.done)
+		l.limiter.Stop()
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// 取出等待者: 优先级高的先取出,相同优先级按等待顺序,优先级更低的等待者记为被推迟
+func TestPriorityLimiterPop(t *testing.T) {
+	tests := []struct {
+		name        string
+		priorities  []int
+		wantOrder   []int
+		wantDelayed []bool
+	}{
+		{"single", []int{0}, []int{0}, []bool{false}},
+		{"same priority is fifo", []int{1, 1, 1}, []int{0, 1, 2}, []bool{false, false, false}},
+		{"higher first", []int{0, 2, 1}, []int{1, 2, 0}, []bool{true, false, true}},
+		{"fifo among highest", []int{0, 2, 1, 2}, []int{1, 3, 2, 0}, []bool{true, false, true, false}},
+		{"negative priority last", []int{-1, 0}, []int{1, 0}, []bool{true, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &priorityLimiter{}
+			waiters := make([]*priorityWaiter, len(tt.priorities))
+			for i, priority := range tt.priorities {
+				waiters[i] = &priorityWaiter{priority: priority, seq: uint64(i)}
+				l.waiters = append(l.waiters, waiters[i])
+			}
+			for _, want := range tt.wantOrder {
+				if got := l.pop(); got != waiters[want] {
+					t.Fatalf("pop() = waiter %d, want waiter %d", got.seq, want)
+				}
+			}
+			if got := l.pop(); got != nil {
+				t.Errorf("pop() on empty = waiter %d, want nil", got.seq)
+			}
+			for i, waiter := range waiters {
+				if waiter.delayed != tt.wantDelayed[i] {
+					t.Errorf("waiter %d delayed = %v, want %v", i, waiter.delayed, tt.wantDelayed[i])
+				}
+			}
+		})
+	}
+}
+
+// 令牌不足时等待中的请求按优先级领取令牌,ctx 结束时放弃等待
+func TestPriorityLimiterWait(t *testing.T) {
+	if l := newPriorityLimiter(0); l != nil {
+		t.Fatal("newPriorityLimiter(0) should return nil")
+	}
+
+	l := newPriorityLimiter(5)
+	defer l.Stop()
+	// 先领取一个令牌,使后续等待者在下一个令牌发放前全部排队
+	if ok, _ := l.Wait(context.Background(), 0); !ok {
+		t.Fatal("first Wait failed")
+	}
+	priorities := []int{0, 2, 1}
+	results := make(chan int, len(priorities))
+	delayed := make([]bool, len(priorities))
+	for i, priority := range priorities {
+		go func() {
+			ok, d := l.Wait(context.Background(), priority)
+			if !ok {
+				t.Errorf("Wait(priority %d) failed", priority)
+			}
+			delayed[i] = d
+			results <- i
+		}()
+		waitForWaiters(t, l, i+1)
+	}
+	var order []int
+	for range priorities {
+		order = append(order, <-results)
+	}
+	if want := []int{1, 2, 0}; !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	if want := []bool{true, false, true}; !slices.Equal(delayed, want) {
+		t.Errorf("delayed = %v, want %v", delayed, want)
+	}
+
+	// 刚领取过令牌,下一个令牌在 ctx 结束之后才会发放
+	l.Wait(context.Background(), 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if ok, _ := l.Wait(ctx, 0); ok {
+		t.Error("Wait should fail when ctx ends before a token is available")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.waiters) != 0 {
+		t.Errorf("%d waiters left after ctx ended", len(l.waiters))
+	}
+}
+
+// 等待限速器中的等待者数量达到 n
+func waitForWaiters(t *testing.T, l *priorityLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		count := len(l.waiters)
+		l.mu.Unlock()
+		if count >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("waiters did not reach %d", n)
+}
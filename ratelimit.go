@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// 每个请求配置每秒最多发送的请求数,通过 -rate 指定,0表示不限速
+var requestRate int64
+
+// 基于 time.Ticker 的限速器,由同一配置的所有工作协程共用
+// Ticker 不会累积未被领取的令牌,因此不会出现突发流量
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+// 创建每秒发放 rate 个令牌的限速器,rate 不大于0时返回 nil,表示不限速
+func newRateLimiter(rate int64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	interval := max(time.Second/time.Duration(rate), 1)
+	return &rateLimiter{ticker: time.NewTicker(interval)}
+}
+
+// 等待获取令牌,ctx 结束时返回 false
+func (l *rateLimiter) Wait(ctx context.Context) bool {
+	if l == nil {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-l.ticker.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// 停止限速器
+func (l *rateLimiter) Stop() {
+	if l != nil {
+		l.ticker.Stop()
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// 完整运行所有请求配置的次数,通过 -repeat 指定
+var repeatRuns = 1
+
+// 依次完整运行 repeatRuns 次所有请求配置,返回每次运行的结果,被中断时不再开始新的运行
+func runRepeated(requestList []RequestConfig, concurrency, totalRequests, timeout int64) [][]Result {
+	runs := make([][]Result, 0, repeatRuns)
+	for run := 1; run <= repeatRuns; run++ {
+		if run > 1 && runCtx.Err() != nil {
+			break
+		}
+		if repeatRuns > 1 {
+			fmt.Printf(tr("repeat_run"), run, repeatRuns)
+		}
+		runs = append(runs, runTest(requestList, concurrency, totalRequests, timeout))
+	}
+	return runs
+}
+
+// 输出每个请求配置在各次运行中的QPS、p95和成功率,以及QPS和p95的最小值、最大值和平均值
+func showRepeatComparison(runs [][]Result) {
+	if len(runs) < 2 {
+		return
+	}
+	fmt.Print(tr("repeat_title"))
+	for index := range runs[0] {
+		fmt.Printf(tr("result_title"), index+1)
+		qpsList := make([]float64, 0, len(runs))
+		p95List := make([]int64, 0, len(runs))
+		for run, results := range runs {
+			// 被中断的运行中可能没有执行到该配置
+			if index >= len(results) {
+				continue
+			}
+			result := results[index]
+			runQPS := qps(result.TotalRequests, result.TotalTime)
+			qpsList = append(qpsList, runQPS)
+			p95List = append(p95List, result.P95Time)
+			fmt.Printf(tr("repeat_row"), run+1, runQPS, MsToSeconds(result.P95Time), ratioPercent(result.SuccessRequests, result.TotalRequests))
+		}
+		var qpsSum float64
+		for _, v := range qpsList {
+			qpsSum += v
+		}
+		fmt.Printf(tr("repeat_qps"), slices.Min(qpsList), slices.Max(qpsList), qpsSum/float64(len(qpsList)))
+		fmt.Printf(tr("repeat_p95"), MsToSeconds(slices.Min(p95List)), MsToSeconds(slices.Max(p95List)), MsToSeconds(average(p95List)))
+	}
+}
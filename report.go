@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"html/template"
+	"runtime"
+	rdebug "runtime/debug"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// 运行清单,记录运行参数和环境,用于复现和审计
+type runManifest struct {
+	Flags      map[string]string `json:"flags"`
+	ConfigFile string            `json:"configFile"`
+	ConfigHash string            `json:"configHash"`
+	Version    string            `json:"version"`
+	GitCommit  string            `json:"gitCommit,omitempty"`
+	GoVersion  string            `json:"goVersion"`
+	StartTime  string            `json:"startTime"`
+	EndTime    string            `json:"endTime"`
+}
+
+// 写入运行清单,包含所有命令行参数的值、配置文件哈希、版本信息和起止时间
+func writeManifest(filePath, configFile string, startTime, endTime time.Time) error {
+	manifest := runManifest{
+		Flags:      make(map[string]string),
+		ConfigFile: configFile,
+		ConfigHash: configHash,
+		Version:    "unknown",
+		GoVersion:  runtime.Version(),
+		StartTime:  startTime.Format(time.RFC3339),
+		EndTime:    endTime.Format(time.RFC3339),
+	}
+	flag.VisitAll(func(f *flag.Flag) {
+		manifest.Flags[f.Name] = f.Value.String()
+	})
+	if info, ok := rdebug.ReadBuildInfo(); ok {
+		manifest.Version = info.Main.Version
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				manifest.GitCommit = setting.Value
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filePath, data)
+}
+
+// 将每个请求配置的结果写入CSV文件,每行一个配置,便于导入电子表格
+func writeResultCSV(filePath string, results []Result) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"config", "url", "method", "total", "success", "failures", "timeouts", "qps", "avg_ms", "max_ms", "p95_ms", "ttfb_avg_ms", "ttfb_p50_ms", "ttfb_p95_ms", "ttfb_p99_ms"})
+	for index, reqResult := range results {
+		writer.Write([]string{
+			strconv.Itoa(index + 1),
+			reqResult.RequestConfig.URL,
+			reqResult.RequestConfig.Method,
+			strconv.FormatInt(reqResult.TotalRequests, 10),
+			strconv.FormatInt(reqResult.SuccessRequests, 10),
+			strconv.FormatInt(reqResult.TotalRequests-reqResult.SuccessRequests, 10),
+			strconv.FormatInt(reqResult.RequestTimeoutNum, 10),
+			strconv.FormatFloat(qps(reqResult.TotalRequests, reqResult.TotalTime), 'f', 2, 64),
+			strconv.FormatInt(reqResult.AvgTime, 10),
+			strconv.FormatInt(reqResult.MaxTime, 10),
+			strconv.FormatInt(reqResult.P95Time, 10),
+			strconv.FormatInt(reqResult.AvgTTFBTime, 10),
+			strconv.FormatInt(reqResult.P50TTFBTime, 10),
+			strconv.FormatInt(reqResult.P95TTFBTime, 10),
+			strconv.FormatInt(reqResult.P99TTFBTime, 10),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return writeFile(filePath, buf.Bytes())
+}
+
+// HTML报告中单个请求配置的数据
+type htmlConfigReport struct {
+	Index        int
+	Result       Result
+	QPS          float64
+	OKQPS        float64
+	SuccessRate  float64
+	Distribution []htmlBucket
+}
+
+// HTML报告耗时分布中的一个区间,Percent 为相对最大区间的柱状图宽度
+type htmlBucket struct {
+	Label   string
+	Count   int
+	Percent float64
+}
+
+// 自包含的HTML报告模板,样式内联,柱状图使用CSS宽度绘制,不依赖外部资源
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"tr": tr,
+	"ms": MsToSeconds,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{tr "html_title"}} - {{.Config}}</title>
+<style>
+body { font-family: sans-serif; margin: 24px; color: #222; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th { background: #f3f3f3; }
+td.url { text-align: left; }
+.bar { background: #4a90d9; height: 14px; }
+.chart td { border: none; padding: 2px 8px; }
+.chart td.track { width: 480px; }
+</style>
+</head>
+<body>
+<h1>{{tr "html_title"}} - {{.Config}}</h1>
+<p>{{.Time}}</p>
+<h2>{{tr "html_summary"}}</h2>
+<table>
+<tr><th>#</th><th>URL</th><th>All-QPS</th><th>OK-QPS</th><th>{{tr "html_col_total"}}</th><th>{{tr "html_col_success"}}</th><th>{{tr "html_col_timeout"}}</th><th>{{tr "html_col_avg"}}</th><th>p50</th><th>p95</th><th>p99</th><th>{{tr "html_col_max"}}</th></tr>
+{{range .Configs}}<tr><td>{{.Index}}</td><td class="url">[{{.Result.RequestConfig.Method}}] {{.Result.RequestConfig.URL}}</td><td>{{printf "%.2f" .QPS}}</td><td>{{printf "%.2f" .OKQPS}}</td><td>{{.Result.TotalRequests}}</td><td>{{.Result.SuccessRequests}} ({{printf "%.2f" .SuccessRate}}%)</td><td>{{.Result.RequestTimeoutNum}}</td><td>{{ms .Result.AvgTime}}</td><td>{{ms .Result.P50Time}}</td><td>{{ms .Result.P95Time}}</td><td>{{ms .Result.P99Time}}</td><td>{{ms .Result.MaxTime}}</td></tr>
+{{end}}</table>
+{{range .Configs}}<h2>#{{.Index}} [{{.Result.RequestConfig.Method}}] {{.Result.RequestConfig.URL}}</h2>
+{{if .Result.TTFBTimes}}<h3>{{tr "html_ttfb"}}</h3>
+<table>
+<tr><th>{{tr "html_col_avg"}}</th><th>p50</th><th>p95</th><th>p99</th><th>{{tr "html_col_max"}}</th></tr>
+<tr><td>{{ms .Result.AvgTTFBTime}}</td><td>{{ms .Result.P50TTFBTime}}</td><td>{{ms .Result.P95TTFBTime}}</td><td>{{ms .Result.P99TTFBTime}}</td><td>{{ms .Result.MaxTTFBTime}}</td></tr>
+</table>
+{{end}}<h3>{{tr "html_distribution"}}</h3>
+<table class="chart">
+{{range .Distribution}}<tr><td>{{.Label}}</td><td class="track"><div class="bar" style="width: {{printf "%.1f" .Percent}}%"></div></td><td>{{.Count}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// 将结果写入自包含的HTML报告,包含每个配置的统计表格和耗时分布柱状图
+func writeResultHTML(filePath string, results []Result) error {
+	data := struct {
+		Config  string
+		Time    string
+		Configs []htmlConfigReport
+	}{
+		Config: configFileName,
+		Time:   time.Now().Format(time.RFC3339),
+	}
+	interval := distributionInterval
+	for index, reqResult := range results {
+		distribution := latencyDistribution(reqResult.RequestsTimes, reqResult.MaxTime, interval)
+		maxCount := slices.Max(distribution)
+		report := htmlConfigReport{
+			Index:       index + 1,
+			Result:      reqResult,
+			QPS:         qps(reqResult.TotalRequests, reqResult.TotalTime),
+			OKQPS:       qps(reqResult.SuccessRequests, reqResult.TotalTime),
+			SuccessRate: ratioPercent(reqResult.SuccessRequests, reqResult.TotalRequests),
+		}
+		for i, count := range distribution {
+			if count == 0 {
+				continue
+			}
+			start := int64(i) * interval
+			label := MsToSeconds(start) + "-" + MsToSeconds(start+interval-1)
+			if i == len(distribution)-1 {
+				label = MsToSeconds(start) + "+"
+			}
+			report.Distribution = append(report.Distribution, htmlBucket{
+				Label:   label,
+				Count:   count,
+				Percent: float64(count) / float64(maxCount) * 100,
+			})
+		}
+		data.Configs = append(data.Configs, report)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	return writeFile(filePath, buf.Bytes())
+}
+
+// 将每个请求配置的耗时分布写入CSV文件,每行为一个耗时区间
+func writeDistributionCSV(filePath string, results []Result) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"config", "url", "start_ms", "end_ms", "count"})
+	for index, reqResult := range results {
+		interval := distributionInterval
+		distribution := latencyDistribution(reqResult.RequestsTimes, reqResult.MaxTime, interval)
+		for i, count := range distribution {
+			start := int64(i) * interval
+			end := start + interval - 1
+			writer.Write([]string{
+				strconv.Itoa(index + 1),
+				reqResult.RequestConfig.URL,
+				strconv.FormatInt(start, 10),
+				strconv.FormatInt(end, 10),
+				strconv.Itoa(count),
+			})
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return writeFile(filePath, buf.Bytes())
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// 逐个请求记录耗时样本并以NDJSON格式写入 -samples 指定的文件,为 nil 时不记录
+var sampleRecorder *sampleWriter
+
+// 单个请求的耗时样本
+type requestSample struct {
+	// 请求配置序号,从1开始
+	Config int `json:"config"`
+	// 请求开始时间,RFC3339格式,精确到纳秒
+	Start     string  `json:"start"`
+	ElapsedMs float64 `json:"elapsedMs"`
+	// 响应状态码,没有收到响应时为0
+	Status int    `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// 样本在测试过程中经过缓冲写入文件,内存占用与总请求数无关
+type sampleWriter struct {
+	mu      sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+	// 第一次写入失败的错误,之后不再写入
+	err error
+}
+
+func newSampleWriter(filePath string) (*sampleWriter, error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return &sampleWriter{file: file, writer: writer, encoder: encoder}, nil
+}
+
+// 记录一个请求的样本,err 为请求失败或读取响应体失败的错误
+func (s *sampleWriter) Record(configIndex int, startTime time.Time, status int, err error) {
+	if s == nil {
+		return
+	}
+	sample := requestSample{
+		Config:    configIndex + 1,
+		Start:     startTime.Format(time.RFC3339Nano),
+		ElapsedMs: durationMs(time.Since(startTime)),
+		Status:    status,
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = s.encoder.Encode(sample)
+	}
+}
+
+// 写入缓冲区中剩余的样本并关闭文件,返回写入过程中的第一个错误
+func (s *sampleWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writer.Flush(); err != nil && s.err == nil {
+		s.err = err
+	}
+	if err := s.file.Close(); err != nil && s.err == nil {
+		s.err = err
+	}
+	return s.err
+}
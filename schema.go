@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// 编译响应的 JSON Schema,schema 为字符串时作为 Schema 文件路径,否则作为内联 Schema
+// 在读取配置文件时编译一次,压测过程中直接使用编译结果
+func compileSchema(schema any, index int) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	if path, ok := schema.(string); ok {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		return compiler.Compile(absPath)
+	}
+
+	// 内联 Schema 重新序列化后使用 jsonschema 的解析方式,保持数值精度
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("config-%d-schema.json", index)
+	if err := compiler.AddResource(url, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
+
+// 使用 JSON Schema 校验响应体,返回单行的错误信息便于统计
+func validateSchema(schema *jsonschema.Schema, body []byte) error {
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if err := schema.Validate(instance); err != nil {
+		return fmt.Errorf("%s", strings.Join(strings.Fields(err.Error()), " "))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"container/heap"
+	"slices"
+)
+
+// 记录的最慢请求数,通过 -slowest 指定,0表示不记录
+var slowestRequests int
+
+// 单个慢请求,URL 为替换请求链和数据文件变量后实际请求的地址
+type SlowRequest struct {
+	Method    string
+	URL       string
+	ElapsedMs int64
+	// 响应状态码,超时的请求为0
+	Status int `json:",omitempty"`
+}
+
+// 按耗时排序的最小堆,堆顶为已记录请求中最快的一个,超过容量时替换堆顶,内存占用与总请求数无关
+type slowestHeap []SlowRequest
+
+func (h slowestHeap) Len() int           { return len(h) }
+func (h slowestHeap) Less(i, j int) bool { return h[i].ElapsedMs < h[j].ElapsedMs }
+func (h slowestHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *slowestHeap) Push(x any)        { *h = append(*h, x.(SlowRequest)) }
+func (h *slowestHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// 记录一个请求,只保留耗时最长的 slowestRequests 个
+func (h *slowestHeap) add(request SlowRequest) {
+	if slowestRequests <= 0 {
+		return
+	}
+	if h.Len() < slowestRequests {
+		heap.Push(h, request)
+	} else if request.ElapsedMs > (*h)[0].ElapsedMs {
+		(*h)[0] = request
+		heap.Fix(h, 0)
+	}
+}
+
+// 按耗时从高到低排序后的请求
+func (h slowestHeap) sorted() []SlowRequest {
+	if len(h) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(h)
+	slices.SortFunc(sorted, func(a, b SlowRequest) int {
+		return int(b.ElapsedMs - a.ElapsedMs)
+	})
+	return sorted
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 解析阶梯并发数列表,如 "10,50,100"
+func parseSteps(steps string) ([]int64, error) {
+	var levels []int64
+	for _, part := range strings.Split(steps, ",") {
+		level, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || level <= 0 {
+			return nil, fmt.Errorf(tr("invalid_step"), part)
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// 阶梯并发模式: 每个请求配置依次在每个并发数下运行 -step-duration 时长,输出并发数与QPS、p95的对应表
+func runSteps(requestList []RequestConfig, levels []int64, timeout int64) {
+	requestList = prepareChain(requestList, timeout)
+	for index, request := range requestList {
+		// 阶梯模式使用 -steps 中的并发数,忽略配置中的并发数
+		request.Concurrency = 0
+		var stepResults []Result
+		for _, level := range levels {
+			if runCtx.Err() != nil {
+				break
+			}
+			fmt.Printf(tr("step_start"), index+1, request.Method, request.URL, level, stepDuration)
+			stepResults = append(stepResults, runSingleConfigTest(index, request, level, 0, timeout, stepDuration))
+		}
+
+		fmt.Printf(tr("result_title"), index+1)
+		fmt.Printf("【URL】:[%s] %s\n", request.Method, request.URL)
+		fmt.Print(tr("step_table_header"))
+		for i, reqResult := range stepResults {
+			fmt.Printf("%-12d%-12.2f%-12.2f%-12s%.2f%%\n",
+				levels[i],
+				qps(reqResult.TotalRequests, reqResult.TotalTime),
+				qps(reqResult.SuccessRequests, reqResult.TotalTime),
+				MsToSeconds(reqResult.P95Time),
+				ratioPercent(reqResult.SuccessRequests, reqResult.TotalRequests),
+			)
+		}
+		fmt.Printf("\n")
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// 清理配置的执行器,保证清理配置只执行一次
+type teardownRunner struct {
+	once     sync.Once
+	requests []RequestConfig
+	timeout  int64
+}
+
+// 将请求配置分为压测配置和清理配置
+func splitTeardown(requestList []RequestConfig) (tests, teardowns []RequestConfig) {
+	for _, request := range requestList {
+		if request.Teardown {
+			teardowns = append(teardowns, request)
+		} else {
+			tests = append(tests, request)
+		}
+	}
+	return tests, teardowns
+}
+
+// 创建清理配置执行器,测试完成或被中断后由 main 调用 Run
+func newTeardownRunner(requests []RequestConfig, timeout int64) *teardownRunner {
+	return &teardownRunner{requests: requests, timeout: timeout}
+}
+
+// 按顺序发送每个清理配置的请求,多次调用时只执行一次
+func (t *teardownRunner) Run() {
+	t.once.Do(func() {
+		for index, request := range t.requests {
+			request = applyVariables(request)
+			// 清理请求使用该配置的超时时间、代理和默认请求头设置
+			handler := newConfigHandler(request, t.timeout)
+			defer handler.Close()
+			if !quiet {
+				fmt.Printf(tr("teardown_start"), index+1, request.Method, request.URL)
+			}
+			reqConfig := request.nextRequest()
+			resp, _, err := handler.NewRequest(context.Background(), reqConfig, nil)
+			if err != nil {
+				fmt.Printf(tr("teardown_failed"), request.Method, request.URL, err)
+				continue
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if !reqConfig.Response.Status.matches(resp.StatusCode) {
+				fmt.Printf(tr("teardown_failed"), request.Method, request.URL,
+					fmt.Sprintf(tr("smoke_status_mismatch"), reqConfig.Response.Status, resp.StatusCode))
+			}
+		}
+	})
+}
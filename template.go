@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 请求模板函数引用,如 {{randint 1 1000}}、{{uuid}}、{{timestamp}},每个请求单独求值
+var templatePattern = regexp.MustCompile(`\{\{\s*(\w+)((?:\s+[^\s{}]+)*)\s*\}\}`)
+
+// 模板函数,args 为参数个数,参数均为整数
+type templateFunc struct {
+	args int
+	call func(args []int64) string
+}
+
+var templateFuncs = map[string]templateFunc{
+	// [min, max] 范围内的随机整数
+	"randint": {args: 2, call: func(args []int64) string {
+		return strconv.FormatInt(args[0]+rand.Int64N(args[1]-args[0]+1), 10)
+	}},
+	// 随机的 UUID v4
+	"uuid": {args: 0, call: func([]int64) string {
+		return newUUID()
+	}},
+	// 当前Unix时间戳,单位:秒
+	"timestamp": {args: 0, call: func([]int64) string {
+		return strconv.FormatInt(time.Now().Unix(), 10)
+	}},
+	// 当前Unix时间戳,单位:毫秒
+	"timestamp_ms": {args: 0, call: func([]int64) string {
+		return strconv.FormatInt(time.Now().UnixMilli(), 10)
+	}},
+}
+
+// 解析模板函数引用,返回函数和参数,调用方需先确认函数名存在
+func parseTemplate(match []string) (templateFunc, []int64, error) {
+	name := match[1]
+	fn := templateFuncs[name]
+	fields := strings.Fields(match[2])
+	if len(fields) != fn.args {
+		return fn, nil, fmt.Errorf(tr("template_args_invalid"), match[0], fn.args)
+	}
+	args := make([]int64, len(fields))
+	for i, field := range fields {
+		arg, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return fn, nil, fmt.Errorf(tr("template_args_invalid"), match[0], fn.args)
+		}
+		args[i] = arg
+	}
+	if name == "randint" {
+		if args[0] > args[1] {
+			return fn, nil, fmt.Errorf(tr("template_range_invalid"), match[0])
+		}
+		// 范围宽度 max-min+1 必须能用 int64 表示,否则求值时溢出
+		if width := args[1] - args[0]; width < 0 || width == math.MaxInt64 {
+			return fn, nil, fmt.Errorf(tr("template_range_overflow"), match[0])
+		}
+	}
+	return fn, args, nil
+}
+
+// 替换字符串中的模板函数引用,读取配置文件时已校验过,未知的函数名和无法解析的引用保留原样
+func renderTemplates(s string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return templatePattern.ReplaceAllStringFunc(s, func(expr string) string {
+		match := templatePattern.FindStringSubmatch(expr)
+		if _, ok := templateFuncs[match[1]]; !ok {
+			return expr
+		}
+		fn, args, err := parseTemplate(match)
+		if err != nil {
+			return expr
+		}
+		return fn.call(args)
+	})
+}
+
+// 校验请求配置中的模板函数引用,返回是否包含模板函数
+// 函数名不是模板函数的 {{name}} 作为普通文本原样发送,如请求体中的 mustache 模板
+func checkTemplates(request RequestConfig) (bool, error) {
+	var found bool
+	var firstErr error
+	transformConfig(request, func(s string) string {
+		for _, match := range templatePattern.FindAllStringSubmatch(s, -1) {
+			if _, ok := templateFuncs[match[1]]; !ok {
+				continue
+			}
+			found = true
+			if _, _, err := parseTemplate(match); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return s
+	})
+	return found, firstErr
+}
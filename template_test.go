@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 校验模板函数: 未知的函数名作为普通文本,randint 的范围宽度不能溢出 int64
+func TestCheckTemplates(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantTemplated bool
+		wantErr       bool
+	}{
+		{"no template", "plain", false, false},
+		{"mustache literal", "Hello {{name}}, {{ greeting }}", false, false},
+		{"uuid", "{{uuid}}", true, false},
+		{"randint", "{{randint 1 1000}}", true, false},
+		{"randint negative range", "{{randint -5 5}}", true, false},
+		{"randint single value", "{{randint 7 7}}", true, false},
+		{"randint widest valid range", "{{randint 1 9223372036854775807}}", true, false},
+		{"randint min greater than max", "{{randint 10 1}}", true, true},
+		{"randint width overflows", "{{randint 0 9223372036854775807}}", true, true},
+		{"randint full int64 range", "{{randint -9223372036854775808 9223372036854775807}}", true, true},
+		{"randint missing argument", "{{randint 1}}", true, true},
+		{"randint non-integer argument", "{{randint a 5}}", true, true},
+		{"uuid with argument", "{{uuid 1}}", true, true},
+		{"mustache next to function", "{{name}}-{{timestamp}}", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templated, err := checkTemplates(RequestConfig{Data: tt.data})
+			if templated != tt.wantTemplated {
+				t.Errorf("templated = %v, want %v", templated, tt.wantTemplated)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 求值时未知的函数名原样保留,randint 结果在范围内
+func TestRenderTemplates(t *testing.T) {
+	if got := renderTemplates("Hello {{name}}"); got != "Hello {{name}}" {
+		t.Errorf("renderTemplates kept %q, want literal text", got)
+	}
+	for range 100 {
+		got := renderTemplates("{{randint 1 9223372036854775807}}")
+		if value, err := strconv.ParseInt(got, 10, 64); err != nil || value < 1 {
+			t.Fatalf("randint rendered %q", got)
+		}
+	}
+	if got := renderTemplates("{{name}}:{{randint 3 3}}"); got != "{{name}}:3" {
+		t.Errorf("renderTemplates = %q, want %q", got, "{{name}}:3")
+	}
+	if got := renderTemplates("{{uuid}}"); len(got) != 36 || strings.Count(got, "-") != 4 {
+		t.Errorf("uuid rendered %q", got)
+	}
+}
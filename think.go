@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// 每个工作协程完成一个请求后、开始下一个请求前的随机思考时间,通过 -think 指定,不计入请求耗时
+var thinkTime thinkRange
+
+// 思考时间范围,包含边界,min 与 max 相同时为固定时长
+type thinkRange struct {
+	min, max time.Duration
+}
+
+// 解析思考时间,格式为单个时长(如 200ms)或 最小值-最大值(如 100ms-500ms),
+// 最小值省略单位时使用最大值的单位,如 100-500ms
+func parseThinkTime(s string) (thinkRange, error) {
+	minStr, maxStr, isRange := strings.Cut(strings.TrimSpace(s), "-")
+	maxDuration, err := time.ParseDuration(strings.TrimSpace(maxStr))
+	if !isRange {
+		maxDuration, err = time.ParseDuration(strings.TrimSpace(minStr))
+	}
+	if err != nil {
+		return thinkRange{}, fmt.Errorf(tr("invalid_think"), s)
+	}
+	minDuration := maxDuration
+	if isRange {
+		minStr = strings.TrimSpace(minStr)
+		if minDuration, err = time.ParseDuration(minStr); err != nil {
+			unit := strings.TrimLeft(strings.TrimSpace(maxStr), "0123456789.")
+			minDuration, err = time.ParseDuration(minStr + unit)
+		}
+	}
+	if err != nil || minDuration < 0 || minDuration > maxDuration {
+		return thinkRange{}, fmt.Errorf(tr("invalid_think"), s)
+	}
+	return thinkRange{min: minDuration, max: maxDuration}, nil
+}
+
+// 等待一个随机的思考时间,被中断或已到 -duration 截止时间时立即返回
+func (t thinkRange) wait(ctx context.Context) {
+	d := t.min
+	if t.max > t.min {
+		d += rand.N(t.max - t.min + 1)
+	}
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// 思考时间的解析: 单个时长、范围以及最小值沿用最大值单位的写法
+func TestParseThinkTime(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    thinkRange
+		wantErr bool
+	}{
+		{"200ms", thinkRange{200 * time.Millisecond, 200 * time.Millisecond}, false},
+		{"0", thinkRange{}, false},
+		{"100ms-500ms", thinkRange{100 * time.Millisecond, 500 * time.Millisecond}, false},
+		{"100ms-1s", thinkRange{100 * time.Millisecond, time.Second}, false},
+		{"100-500ms", thinkRange{100 * time.Millisecond, 500 * time.Millisecond}, false},
+		{"1.5-2s", thinkRange{1500 * time.Millisecond, 2 * time.Second}, false},
+		{" 100ms - 500ms ", thinkRange{100 * time.Millisecond, 500 * time.Millisecond}, false},
+		{"1s-1s", thinkRange{time.Second, time.Second}, false},
+		{"", thinkRange{}, true},
+		{"abc", thinkRange{}, true},
+		{"200", thinkRange{}, true},
+		{"500ms-100ms", thinkRange{}, true},
+		{"-100ms", thinkRange{}, true},
+		{"100ms-", thinkRange{}, true},
+		{"100ms-500", thinkRange{}, true},
+		{"a-500ms", thinkRange{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseThinkTime(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseThinkTime(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseThinkTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,144 +1,1358 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptrace"
 	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
 )
 
 type Response struct {
-	Status int                    `json:"status"`
+	Status expectedStatus         `json:"status"`
 	Data   map[string]interface{} `json:"field"`
+	// 单调字段的gjson路径,同一协程内的连续请求中该数值不允许递减
+	Monotonic string `json:"monotonic,omitempty"`
+	// 字段类型断言,key为gjson路径,值为 string/number/bool/array/object/null
+	Types map[string]string `json:"types,omitempty"`
+	// 期望的响应结构(所有key路径),一般由 -assert-shape 从基准文件加载
+	Shape []string `json:"shape,omitempty"`
+	// 支持 and/or/not 组合的响应断言树
+	Assert *AssertNode `json:"assert,omitempty"`
+	// 期望的最低成功QPS,未配置时使用 -min-qps 参数
+	MinQPS float64 `json:"minQPS,omitempty"`
+	// 响应体需要符合的 JSON Schema,值为对象时作为内联 Schema,为字符串时作为 Schema 文件路径
+	Schema any `json:"schema,omitempty"`
+	// 读取配置文件时编译好的 Schema
+	schema *jsonschema.Schema
+	// Data 中值为 regex:表达式 的字段编译好的正则表达式,key为字段路径
+	patterns map[string]*regexp.Regexp
+	// Data 中值为 exists:、len:、contains: 结构断言的字段解析好的断言,key为字段路径
+	operators map[string]fieldOperator
+	// 期望的响应头,key为响应头名称(不区分大小写),值需要完全一致
+	Headers map[string]string `json:"headers,omitempty"`
+	// 期望的响应体,需要完全一致,WebSocket配置中为期望的回复消息
+	Body string `json:"body,omitempty"`
+	// 响应 Set-Cookie 断言,key为Cookie名称
+	Cookies map[string]CookieAssert `json:"cookies,omitempty"`
+	// 校验的组合方式,all(默认)表示状态码和所有校验都通过才算成功,any 表示任意一项通过即算成功
+	Match string `json:"match,omitempty"`
+}
+
+// 校验组合方式
+const (
+	matchAll = "all"
+	matchAny = "any"
+)
+
+// 单个响应的校验结果,每个字段、类型、响应头等校验各算一项
+type responseChecks struct {
+	passed   int
+	failures []string
+}
+
+func (c *responseChecks) pass() {
+	c.passed++
+}
+
+func (c *responseChecks) fail(message string) {
+	c.failures = append(c.failures, message)
+}
+
+// 按 Match 组合状态码和其他校验的结果,判断请求是否成功
+func (r Response) matched(statusOK bool, checks responseChecks) bool {
+	if r.Match == matchAny {
+		return statusOK || checks.passed > 0
+	}
+	return statusOK && len(checks.failures) == 0
+}
+
+// 期望的响应状态码,每一项为具体状态码(如 "200")或状态码范围(如 "2xx"),为空时期望200
+// 配置文件中可以是数字、字符串或它们组成的数组,如 200、"2xx"、[200, 201, 204]、["2xx", 304]
+type expectedStatus []string
+
+func (s *expectedStatus) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	values, ok := value.([]any)
+	if !ok {
+		values = []any{value}
+	}
+	*s = nil
+	for _, item := range values {
+		var status string
+		switch v := item.(type) {
+		case float64:
+			status = strconv.FormatFloat(v, 'f', -1, 64)
+		case string:
+			status = strings.ToLower(v)
+		case nil:
+			continue
+		}
+		if !validStatus(status) {
+			return fmt.Errorf(tr("status_invalid"), string(data))
+		}
+		*s = append(*s, status)
+	}
+	return nil
+}
+
+func (s expectedStatus) MarshalJSON() ([]byte, error) {
+	values := make([]any, len(s))
+	for i, status := range s {
+		if code, err := strconv.Atoi(status); err == nil {
+			values[i] = code
+		} else {
+			values[i] = status
+		}
+	}
+	if len(values) == 1 {
+		return json.Marshal(values[0])
+	}
+	return json.Marshal(values)
+}
+
+// 是否为 100-599 的状态码或 1xx-5xx 的状态码范围
+func validStatus(status string) bool {
+	if len(status) != 3 || status[0] < '1' || status[0] > '5' {
+		return false
+	}
+	if status[1:] == "xx" {
+		return true
+	}
+	return status[1] >= '0' && status[1] <= '9' && status[2] >= '0' && status[2] <= '9'
+}
+
+// 状态码是否符合期望
+func (s expectedStatus) matches(code int) bool {
+	if len(s) == 0 {
+		return code == http.StatusOK
+	}
+	for _, status := range s {
+		if status[1:] == "xx" {
+			if code/100 == int(status[0]-'0') {
+				return true
+			}
+		} else if status == strconv.Itoa(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s expectedStatus) String() string {
+	if len(s) == 0 {
+		return strconv.Itoa(http.StatusOK)
+	}
+	return strings.Join(s, ",")
+}
+
+// 响应 Cookie 断言,Cookie 必须存在,只校验配置了的属性
+type CookieAssert struct {
+	HttpOnly *bool `json:"httpOnly,omitempty"`
+	Secure   *bool `json:"secure,omitempty"`
+}
+
+// 校验响应的 Cookie,返回校验失败的原因
+func checkCookies(expected map[string]CookieAssert, cookies []*http.Cookie) []string {
+	var failures []string
+	for name, cookieAssert := range expected {
+		index := slices.IndexFunc(cookies, func(c *http.Cookie) bool {
+			return c.Name == name
+		})
+		if index < 0 {
+			failures = append(failures, fmt.Sprintf(tr("cookie_missing"), name))
+			continue
+		}
+		cookie := cookies[index]
+		if cookieAssert.HttpOnly != nil && *cookieAssert.HttpOnly != cookie.HttpOnly {
+			failures = append(failures, fmt.Sprintf(tr("cookie_attr_mismatch"), name, "HttpOnly", *cookieAssert.HttpOnly, cookie.HttpOnly))
+		}
+		if cookieAssert.Secure != nil && *cookieAssert.Secure != cookie.Secure {
+			failures = append(failures, fmt.Sprintf(tr("cookie_attr_mismatch"), name, "Secure", *cookieAssert.Secure, cookie.Secure))
+		}
+	}
+	return failures
 }
 
 // 请求配置结构体，用于从JSON文件读取请求信息
 type RequestConfig struct {
-	URL      string                 `json:"url"`
-	Method   string                 `json:"method,omitempty"`
+	URL    string `json:"url"`
+	Method string `json:"method,omitempty"`
+	// 按权重随机选择的请求方法,如 {"GET": 80, "POST": 20},配置后忽略 Method
+	Methods  map[string]int         `json:"methods,omitempty"`
 	Params   map[string]interface{} `json:"params,omitempty"`
 	Data     any                    `json:"data,omitempty"`
 	Headers  map[string]string      `json:"headers,omitempty"`
 	Response Response               `json:"response"`
+	// params 与URL中已有查询参数同名时的处理方式,append(默认)追加为多个值,replace 覆盖URL中的值
+	ParamsMode string `json:"paramsMode,omitempty"`
+	// 是否使用gzip压缩请求体并设置 Content-Encoding: gzip
+	CompressRequest bool `json:"compressRequest,omitempty"`
+	// 需要重试的响应状态码,如 [502, 503],其他状态码直接记录结果
+	RetryOn []int `json:"retryOn,omitempty"`
+	// 最大重试次数,配置了 RetryOn 但未配置时默认为 defaultMaxRetries
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// 幂等键请求头名称,如 Idempotency-Key,配置后每个请求使用相同的随机键发送两次,
+	// 第二次响应的状态码和响应体必须与第一次一致
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// 按顺序和原始大小写发送的请求头,如 [["x-api-key", "abc"], ["Accept", "*/*"]]
+	// 配置后该请求通过 orderedHeaderTransport 发送,每个请求使用独立连接
+	OrderedHeaders [][2]string `json:"orderedHeaders,omitempty"`
+	// 请求体文件路径,配置后读取该文件内容原样作为请求体,忽略 Data
+	BodyFile string `json:"bodyFile,omitempty"`
+	// 读取配置文件时加载的请求体文件内容
+	bodyFileData []byte
+	// 请求协议,http(默认)、grpc 或 ws,为 grpc 时 URL 为服务地址,如 grpc://127.0.0.1:50051,
+	// 通过服务端反射调用 Service 服务的 Method 一元方法,Data 为JSON格式的请求消息;
+	// 为 ws 时 URL 为 ws:// 或 wss:// 地址,每个请求在工作协程的连接上发送 Data 并等待一条回复
+	Protocol string `json:"protocol,omitempty"`
+	Service  string `json:"service,omitempty"`
+	// 是否不发送默认请求头(User-Agent、Accept、Accept-Language),-user-agent 指定的 User-Agent 仍然发送
+	NoDefaultHeaders bool `json:"noDefaultHeaders,omitempty"`
+	// 该配置使用的代理地址,支持 http、https、socks5,不为空时覆盖 -proxy
+	Proxy string `json:"proxy,omitempty"`
+	// 读取配置文件时解析的代理地址
+	proxyURL *url.URL
+	// 认证信息,根据类型生成 Authorization 请求头,headers 中的 Authorization 优先
+	Auth *AuthConfig `json:"auth,omitempty"`
+	// CSV数据文件路径,表头为变量名,每个请求按顺序使用一行数据替换 url、headers、params、data、form 中的 ${列名}
+	DataFile string `json:"dataFile,omitempty"`
+	// 读取配置文件时加载的数据文件内容
+	dataRows *dataRows
+	// -f 为目录时该配置所在的文件名
+	sourceFile string
+	// 是否包含 {{randint 1 1000}} 等模板函数引用,读取配置文件时检查
+	templated bool
+	// multipart/form-data 请求的表单字段和文件,Files 的key为字段名,值为文件路径
+	// 配置任意一项后以 multipart/form-data 发送,忽略 Data 和 BodyFile
+	Form  map[string]string `json:"form,omitempty"`
+	Files map[string]string `json:"files,omitempty"`
+	// 读取配置文件时加载的文件字段内容,key为字段名
+	fileData map[string][]byte
+	// 该配置的并发数和总请求数,不为0时覆盖 -c 和 -n
+	Concurrency   int64 `json:"concurrency,omitempty"`
+	TotalRequests int64 `json:"totalRequests,omitempty"`
+	// 从响应中提取变量,key为gjson路径,值为变量名,后续配置可以通过 ${变量名} 引用
+	// 压测前会先为该配置单独发送一次请求进行提取
+	Extract map[string]string `json:"extract,omitempty"`
+	// 清理配置,不参与压测,在所有配置测试完成后或收到中断信号时只发送一次,用于清理测试数据
+	Teardown bool `json:"teardown,omitempty"`
+	// 混合模式(-mixed)下该配置的流量权重,未配置时为1
+	Weight int `json:"weight,omitempty"`
+	// 混合模式下 -rate 限速时的优先级,令牌不足时优先级高的配置先发送,默认0
+	Priority int `json:"priority,omitempty"`
+	// 是否跟随重定向,未配置时由 -no-redirect 决定,为 false 时校验原始的3xx响应
+	FollowRedirects *bool `json:"followRedirects,omitempty"`
+	// 该配置的超时时间,数字表示秒,字符串为时长,如 "500ms"、"1m",不为0时覆盖 -t
+	Timeout configDuration `json:"timeout,omitempty"`
+}
+
+// 配置文件中的时长,支持数字(秒)或 time.ParseDuration 格式的字符串
+type configDuration time.Duration
+
+func (d *configDuration) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case float64:
+		*d = configDuration(v * float64(time.Second))
+	case string:
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = configDuration(duration)
+	case nil:
+		*d = 0
+	default:
+		return fmt.Errorf(tr("duration_invalid"), string(data))
+	}
+	return nil
+}
+
+func (d configDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// 单个请求实际使用的配置: 配置了数据文件时使用下一行数据替换变量引用,包含模板函数时求值,否则原样返回
+func (c RequestConfig) nextRequest() RequestConfig {
+	if c.dataRows != nil {
+		c = c.dataRows.apply(c)
+	}
+	if c.templated {
+		c = transformConfig(c, renderTemplates)
+	}
+	return c
+}
+
+// 混合模式下的流量权重,未配置时为1
+func (c RequestConfig) weight() int {
+	if c.Weight == 0 {
+		return 1
+	}
+	return c.Weight
 }
 
+// 是否跟随重定向,配置中的 FollowRedirects 优先于 -no-redirect
+func (c RequestConfig) followRedirects() bool {
+	if c.FollowRedirects != nil {
+		return *c.FollowRedirects
+	}
+	return !noRedirect
+}
+
+// 获取该配置的超时时间,未配置时使用 -t 指定的秒数
+func (c RequestConfig) timeout(defaultSeconds int64) time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout)
+	}
+	return time.Duration(defaultSeconds) * time.Second
+}
+
+// 认证配置,Type 为 basic 时使用 User 和 Pass,为 bearer 时使用 Token
+type AuthConfig struct {
+	Type  string `json:"type"`
+	User  string `json:"user,omitempty"`
+	Pass  string `json:"pass,omitempty"`
+	Token string `json:"token,omitempty"`
+}
+
+// 生成 Authorization 请求头的值
+func (a *AuthConfig) header() string {
+	switch strings.ToLower(a.Type) {
+	case "basic":
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(a.User+":"+a.Pass))
+	case "bearer":
+		return "Bearer " + a.Token
+	}
+	return ""
+}
+
+// 校验认证类型
+func (a *AuthConfig) Validate() error {
+	switch strings.ToLower(a.Type) {
+	case "basic", "bearer":
+		return nil
+	}
+	return fmt.Errorf(tr("auth_type_invalid"), a.Type)
+}
+
+// 默认最大重试次数
+const defaultMaxRetries = 3
+
+// 首次重试的等待时间,之后每次重试翻倍
+const retryBackoff = 100 * time.Millisecond
+
+// 获取最大重试次数,未配置 RetryOn 时不重试
+func (c RequestConfig) maxRetries() int {
+	if len(c.RetryOn) == 0 {
+		return 0
+	}
+	if c.MaxRetries <= 0 {
+		return defaultMaxRetries
+	}
+	return c.MaxRetries
+}
+
+// 是否对所有请求配置启用请求体gzip压缩,通过 -compress-request 参数指定
+var compressRequest bool
+
+// 是否以 HTTP/1.0 发送请求(不使用长连接和分块传输),通过 -http10 参数指定
+var http10 bool
+
 // RequestHandler 请求处理器结构体
 type RequestHandler struct {
-	client         *http.Client
+	client *http.Client
+	// 发送有序请求头的客户端
+	orderedClient  *http.Client
 	defaultHeaders map[string]string
+	// 表单和文件不随请求变化时创建处理器时构建好的 multipart/form-data 请求体和 Content-Type,为 nil 时每个请求单独构建
+	multipartBody []byte
+	multipartType string
+	// gRPC连接和通过反射获取的方法
+	grpc grpcClients
+	// 空闲的WebSocket连接
+	ws wsClients
+}
+
+// 是否使用 Cookie jar 在请求之间保持会话,通过 -cookies 指定
+var useCookieJar bool
+
+// 是否不跟随重定向,通过 -no-redirect 指定
+var noRedirect bool
+
+// 请求上下文中标记不跟随重定向的key
+type noRedirectKey struct{}
+
+// 重定向策略,请求上下文标记了不跟随重定向时直接返回3xx响应,否则与默认策略一样最多跟随10次
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if disabled, _ := req.Context().Value(noRedirectKey{}).(bool); disabled {
+		return http.ErrUseLastResponse
+	}
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// HTTPS请求使用的TLS配置,由 -insecure、-cert、-key 参数生成,为 nil 时使用默认配置
+var tlsConfig *tls.Config
+
+// 根据参数生成TLS配置,未指定任何参数时返回 nil
+func newTLSConfig(insecure bool, certFile, keyFile string) (*tls.Config, error) {
+	if !insecure && certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: insecure}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// 连接池参数,通过 -max-idle-conns、-max-idle-conns-per-host 指定,0表示使用 net/http 的默认值
+var maxIdleConns, maxIdleConnsPerHost int
+
+// 是否禁用长连接,通过 -no-keepalive 指定,禁用后每个请求都建立新连接
+var disableKeepAlives bool
+
+// 所有请求使用的代理地址,通过 -proxy 指定,为 nil 时与默认一样使用环境变量中的代理设置
+var proxyURL *url.URL
+
+// 解析代理地址,只支持 http、https、socks5(socks5h)
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch parsed.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return parsed, nil
+	}
+	return nil, fmt.Errorf(tr("proxy_scheme_invalid"), rawURL)
+}
+
+// 根据TLS配置、连接池参数、代理和本地地址创建 Transport,proxy 为 nil 时使用 -proxy,都未指定时返回 nil,使用默认的 Transport
+func newTransport(proxy *url.URL) *http.Transport {
+	if proxy == nil {
+		proxy = proxyURL
+	}
+	if tlsConfig == nil && maxIdleConns == 0 && maxIdleConnsPerHost == 0 && !disableKeepAlives && proxy == nil && len(bindAddrs) == 0 {
+		return nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if len(bindAddrs) > 0 {
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return bindDialer(defaultDialer, addr).DialContext(ctx, network, addr)
+		}
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if maxIdleConns > 0 {
+		transport.MaxIdleConns = maxIdleConns
+	}
+	if maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	}
+	transport.DisableKeepAlives = disableKeepAlives
+	return transport
 }
 
 // NewRequestHandler 创建新的请求处理器
 func NewRequestHandler(timeout time.Duration) *RequestHandler {
+	client := &http.Client{
+		Timeout:       timeout,
+		CheckRedirect: checkRedirect,
+	}
+	if transport := newTransport(nil); transport != nil {
+		client.Transport = transport
+	}
+	orderedClient := &http.Client{
+		Timeout:       timeout,
+		Transport:     &orderedHeaderTransport{tlsConfig: tlsConfig, proxy: proxyURL},
+		CheckRedirect: checkRedirect,
+	}
+	// 启用 -cookies 时同一处理器的所有请求共用一个 Cookie jar,保存响应设置的 Cookie 并在后续请求中发送
+	if useCookieJar {
+		jar, _ := cookiejar.New(nil)
+		client.Jar = jar
+		orderedClient.Jar = jar
+	}
 	return &RequestHandler{
-		client: &http.Client{
-			Timeout: timeout,
-		},
-		defaultHeaders: map[string]string{
-			"User-Agent":      "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-			"Accept-Language": "zh-CN,zh;q=0.9,en;q=0.8",
-			"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8",
-		},
+		client:         client,
+		orderedClient:  orderedClient,
+		defaultHeaders: defaultRequestHeaders(noDefaultHeaders),
+	}
+}
+
+// 默认的 User-Agent,可以通过 -user-agent 覆盖
+const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// 整个运行使用的 User-Agent,通过 -user-agent 指定,为空时使用 defaultUserAgent
+var userAgent string
+
+// 是否不发送默认请求头,通过 -no-default-headers 指定
+var noDefaultHeaders bool
+
+// 每个请求默认发送的请求头,配置中的同名请求头优先
+// disabled 为 true 时只保留 -user-agent 指定的 User-Agent
+func defaultRequestHeaders(disabled bool) map[string]string {
+	headers := make(map[string]string)
+	if userAgent != "" {
+		headers["User-Agent"] = userAgent
+	}
+	if disabled {
+		return headers
+	}
+	if userAgent == "" {
+		headers["User-Agent"] = defaultUserAgent
+	}
+	headers["Accept-Language"] = "zh-CN,zh;q=0.9,en;q=0.8"
+	headers["Accept"] = "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8"
+	return headers
+}
+
+// Close 关闭处理器缓存的gRPC连接、空闲的WebSocket连接和HTTP长连接,处理器使用完毕后调用
+func (h *RequestHandler) Close() {
+	h.grpc.close()
+	h.ws.close()
+	// 默认的 Transport 由所有处理器共用,只关闭处理器独立创建的 Transport 的空闲连接
+	if h.client.Transport != nil {
+		h.client.CloseIdleConnections()
+	}
+}
+
+// 创建请求配置使用的请求处理器,使用该配置的超时时间、代理和默认请求头设置
+func newConfigHandler(request RequestConfig, timeout int64) *RequestHandler {
+	handler := NewRequestHandler(request.timeout(timeout))
+	if request.proxyURL != nil {
+		handler.client.Transport = newTransport(request.proxyURL)
+		handler.orderedClient.Transport = &orderedHeaderTransport{tlsConfig: tlsConfig, proxy: request.proxyURL}
+	}
+	if request.NoDefaultHeaders {
+		handler.defaultHeaders = defaultRequestHeaders(true)
+	}
+	// 没有数据文件和模板函数时每个请求的表单都相同,只构建一次 multipart 请求体
+	if (len(request.Form) > 0 || len(request.Files) > 0) && request.dataRows == nil && !request.templated {
+		if body, contentType, err := handler.createMultipartBody(request.Form, request.Files, request.fileData); err == nil {
+			handler.multipartBody, handler.multipartType = body, contentType
+		}
+	}
+	return handler
+}
+
+// 通过 httptrace 记录的请求信息
+type RequestTiming struct {
+	// 构建请求(发送前)的耗时,即客户端开销
+	Prepare time.Duration
+	// 建立WebSocket连接的握手耗时,复用连接时为0
+	Handshake time.Duration
+	// 实际发送的请求体压缩前后的字节数,仅在启用请求体压缩时记录
+	BodySize           int64
+	CompressedBodySize int64
+	// 首字节时间点
+	FirstByte time.Time
+	// 建立TCP连接失败的错误,拨号可能在请求返回后仍在其他协程中进行,需要加锁访问
+	mu         sync.Mutex
+	connectErr error
+	// 连接各阶段的开始时间和耗时,仅在 -trace 时记录,复用连接时没有这些阶段
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	phases       ConnPhases
+}
+
+// 建立连接各阶段的耗时,为0表示该请求没有进行该阶段
+type ConnPhases struct {
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+}
+
+// 记录阶段开始时间
+func (t *RequestTiming) markStart(start *time.Time) {
+	t.mu.Lock()
+	*start = time.Now()
+	t.mu.Unlock()
+}
+
+// 记录阶段耗时,未记录开始时间时忽略
+func (t *RequestTiming) markDone(start *time.Time, phase *time.Duration) {
+	t.mu.Lock()
+	if !start.IsZero() {
+		*phase = time.Since(*start)
+	}
+	t.mu.Unlock()
+}
+
+// 获取建立连接各阶段的耗时
+func (t *RequestTiming) Phases() ConnPhases {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.phases
+}
+
+// 记录建立连接失败的错误
+func (t *RequestTiming) setConnectErr(err error) {
+	t.mu.Lock()
+	t.connectErr = err
+	t.mu.Unlock()
+}
+
+// 获取建立连接失败的错误
+func (t *RequestTiming) ConnectErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connectErr
+}
+
+// NewRequest 根据配置构建并发送请求,gRPC和WebSocket配置按各自的协议发送
+// timing 不为空时通过 httptrace 记录请求各阶段的时间点和建立连接的错误
+func (h *RequestHandler) NewRequest(ctx context.Context, config RequestConfig, timing *RequestTiming) (*http.Response, *http.Client, error) {
+	switch config.Protocol {
+	case protocolGRPC:
+		return h.newGRPCRequest(ctx, config, timing)
+	case protocolWebSocket:
+		return h.newWebSocketRequest(ctx, config, timing)
 	}
+	req, client, err := h.BuildRequest(ctx, config, timing)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 发送请求
+	resp, err := client.Do(req)
+	if err == nil {
+		decodeResponseBody(resp)
+	}
+
+	return resp, client, err
 }
 
-// BuildRequest
-func (h *RequestHandler) NewRequest(config RequestConfig) (*http.Response, *http.Client, error) {
+// BuildRequest 根据配置构建HTTP请求,返回请求和发送该请求使用的客户端
+func (h *RequestHandler) BuildRequest(ctx context.Context, config RequestConfig, timing *RequestTiming) (*http.Request, *http.Client, error) {
+	prepareStart := time.Now()
 	parsedURL, err := url.Parse(config.URL)
 	if err != nil {
-		return nil, nil, fmt.Errorf("URL解析错误: %v", err)
+		return nil, nil, fmt.Errorf(tr("url_parse_error"), err)
 	}
 
-	h.processURLParams(parsedURL, config.Params)
-	reqBody, err := h.createRequestBody(config.Data)
+	h.processURLParams(parsedURL, config.Params, config.ParamsMode == paramsReplace)
+	compress := compressRequest || config.CompressRequest
+	data := config.Data
+	if config.BodyFile != "" {
+		data = config.bodyFileData
+	}
+	var multipartType string
+	if h.multipartBody != nil {
+		data, multipartType = h.multipartBody, h.multipartType
+	} else if len(config.Form) > 0 || len(config.Files) > 0 {
+		if data, multipartType, err = h.createMultipartBody(config.Form, config.Files, config.fileData); err != nil {
+			return nil, nil, err
+		}
+	}
+	// 先编码为字节,以便记录压缩前的请求体大小
+	var rawBody []byte
+	if data != nil {
+		if rawBody, err = encodeRequestBody(data); err != nil {
+			return nil, nil, err
+		}
+		data = rawBody
+	}
+	newBody, err := h.createRequestBody(data, compress)
 	if err != nil {
 		return nil, nil, err
 	}
+	var reqBody io.Reader
+	if newBody != nil {
+		reqBody = newBody()
+	}
 
-	method := h.getMethod(config.Method)
-	req, err := http.NewRequest(method, parsedURL.String(), reqBody)
+	method := h.getMethod(config)
+	req, err := http.NewRequestWithContext(ctx, method, parsedURL.String(), reqBody)
 	if err != nil {
-		return nil, nil, fmt.Errorf("创建请求失败: %v", err)
+		return nil, nil, fmt.Errorf(tr("create_request_error"), err)
+	}
+	// 压缩后的大小即实际发送的 Content-Length
+	if timing != nil && compress && rawBody != nil {
+		timing.BodySize = int64(len(rawBody))
+		timing.CompressedBodySize = req.ContentLength
 	}
 
-	h.setRequestHeaders(req, config.Headers)
+	if timing != nil {
+		trace := &httptrace.ClientTrace{
+			ConnectDone: func(network, addr string, err error) {
+				if err != nil {
+					timing.setConnectErr(err)
+					return
+				}
+				timing.markDone(&timing.connectStart, &timing.phases.Connect)
+			},
+			GotFirstResponseByte: func() {
+				timing.FirstByte = time.Now()
+			},
+		}
+		if traceRequest {
+			trace.DNSStart = func(httptrace.DNSStartInfo) {
+				timing.markStart(&timing.dnsStart)
+			}
+			trace.DNSDone = func(httptrace.DNSDoneInfo) {
+				timing.markDone(&timing.dnsStart, &timing.phases.DNS)
+			}
+			trace.ConnectStart = func(network, addr string) {
+				timing.markStart(&timing.connectStart)
+			}
+			trace.TLSHandshakeStart = func() {
+				timing.markStart(&timing.tlsStart)
+			}
+			trace.TLSHandshakeDone = func(tls.ConnectionState, error) {
+				timing.markDone(&timing.tlsStart, &timing.phases.TLS)
+			}
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	}
 
-	// 发送请求
-	resp, err := h.client.Do(req)
+	h.setRequestHeaders(req, config.Headers, config.Auth)
+	// multipart 请求的 Content-Type 包含分隔符,覆盖配置中的 Content-Type
+	if multipartType != "" {
+		req.Header.Set("Content-Type", multipartType)
+	}
+	if compress && reqBody != nil {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 
-	return resp, h.client, err
+	if http10 {
+		req.Proto = "HTTP/1.0"
+		req.ProtoMajor = 1
+		req.ProtoMinor = 0
+		req.Close = true
+	}
+
+	if !config.followRedirects() {
+		req = req.WithContext(context.WithValue(req.Context(), noRedirectKey{}, true))
+	}
+
+	// net/http 客户端总是以 HTTP/1.1 或 HTTP/2 发送请求,HTTP/1.0 请求同样通过 orderedHeaderTransport 直接写入
+	client := h.client
+	if len(config.OrderedHeaders) > 0 || http10 {
+		req = req.WithContext(context.WithValue(req.Context(), orderedHeadersKey{}, config.OrderedHeaders))
+		client = h.orderedClient
+	}
+
+	if timing != nil {
+		timing.Prepare = time.Since(prepareStart)
+	}
+	return req, client, nil
 }
 
-func (h *RequestHandler) processURLParams(u *url.URL, params map[string]interface{}) {
+// paramsMode 的可选值
+const (
+	paramsAppend  = "append"
+	paramsReplace = "replace"
+)
+
+// 将 params 添加到URL的查询参数中,replace 为 true 时覆盖URL中已有的同名参数
+func (h *RequestHandler) processURLParams(u *url.URL, params map[string]interface{}, replace bool) {
 	if params != nil {
 		query := u.Query()
 		for key, value := range params {
-			query.Add(key, fmt.Sprintf("%v", value))
+			if replace {
+				query.Set(key, fmt.Sprintf("%v", value))
+			} else {
+				query.Add(key, fmt.Sprintf("%v", value))
+			}
 		}
 		u.RawQuery = query.Encode()
 	}
 }
 
-func (h *RequestHandler) createRequestBody(data any) (io.Reader, error) {
+// 请求体工厂,每次调用都返回一个独立的读取器
+// 请求体内容以不可变的字节保存,多个协程并发发送请求时不会共享同一个 io.Reader
+type bodyFactory func() io.Reader
+
+// 根据 data 创建请求体工厂,compress 为 true 时使用gzip压缩,data 为空时返回 nil
+func (h *RequestHandler) createRequestBody(data any, compress bool) (bodyFactory, error) {
 	if data == nil {
 		return nil, nil
 	}
-	// 判断data为字符串
-	if str, ok := data.(string); ok {
-		return strings.NewReader(str), nil
+	dataBytes, err := encodeRequestBody(data)
+	if err != nil {
+		return nil, err
+	}
+	if compress {
+		dataBytes, err = gzipBytes(dataBytes)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return func() io.Reader {
+		return bytes.NewReader(dataBytes)
+	}, nil
+}
+
+// 构建 multipart/form-data 请求体,返回请求体和包含分隔符的 Content-Type
+// 字段和文件按名称排序写入,文件内容使用读取配置文件时加载的 fileData
+func (h *RequestHandler) createMultipartBody(form, files map[string]string, fileData map[string][]byte) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, name := range slices.Sorted(maps.Keys(form)) {
+		if err := writer.WriteField(name, form[name]); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		part, err := writer.CreateFormFile(name, filepath.Base(files[name]))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(fileData[name]); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
 
+// 将 data 编码为请求体字节,字符串原样发送,其他类型序列化为JSON
+func encodeRequestBody(data any) ([]byte, error) {
+	// 判断data为字符串或请求体文件内容
+	switch body := data.(type) {
+	case string:
+		return []byte(body), nil
+	case []byte:
+		return body, nil
+	}
 	dataBytes, err := json.Marshal(data)
 	if err != nil {
-		return nil, fmt.Errorf("数据序列化错误: %v", err)
+		return nil, fmt.Errorf(tr("marshal_data_error"), err)
 	}
-	return bytes.NewBuffer(dataBytes), nil
+	return dataBytes, nil
 }
 
-func (h *RequestHandler) getMethod(method string) string {
-	if method == "" {
+// 按 Content-Encoding 解压响应体,使字段校验和响应大小统计基于解压后的内容
+// net/http 只在自动添加 Accept-Encoding 时解压gzip响应,配置了 Accept-Encoding 请求头或通过 orderedHeaderTransport 发送时需要自行解压
+func decodeResponseBody(resp *http.Response) {
+	if resp.Uncompressed {
+		return
+	}
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "deflate" {
+		return
+	}
+	resp.Body = &decodedBody{body: resp.Body, encoding: encoding}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// 解压的响应体,首次读取时才创建解压器,空响应体(如 HEAD 请求)不会因缺少压缩头而报错
+type decodedBody struct {
+	body     io.ReadCloser
+	encoding string
+	reader   io.Reader
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.reader == nil {
+		reader, err := newDecodeReader(b.body, b.encoding)
+		if err != nil {
+			return 0, err
+		}
+		b.reader = reader
+	}
+	return b.reader.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	return b.body.Close()
+}
+
+// 创建解压器,deflate 按规范为zlib格式,部分服务端发送不带zlib头的原始deflate数据,通过头部校验区分
+func newDecodeReader(body io.Reader, encoding string) (io.Reader, error) {
+	if encoding == "gzip" {
+		return gzip.NewReader(body)
+	}
+	buffered := bufio.NewReader(body)
+	header, _ := buffered.Peek(2)
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+// gzip压缩数据
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf(tr("compress_body_error"), err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf(tr("compress_body_error"), err)
+	}
+	return buf.Bytes(), nil
+}
+
+// 获取请求方法,配置了 Methods 时按权重随机选择
+func (h *RequestHandler) getMethod(config RequestConfig) string {
+	if picked := pickWeightedMethod(config.Methods); picked != "" {
+		return picked
+	}
+	if config.Method == "" {
 		return "GET"
 	}
-	return method
+	return config.Method
 }
 
-func (h *RequestHandler) setRequestHeaders(req *http.Request, headers map[string]string) {
+// 支持的HTTP请求方法
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+// 将请求配置的 Method 和 Methods 转为大写并校验是否为支持的HTTP请求方法,返回无效的方法
+func normalizeMethods(request *RequestConfig) error {
+	method := strings.ToUpper(request.Method)
+	if method != "" && !slices.Contains(httpMethods, method) {
+		return fmt.Errorf("%q", request.Method)
+	}
+	request.Method = method
+	if len(request.Methods) == 0 {
+		return nil
+	}
+	// 大小写不同的同一方法合并权重
+	methods := make(map[string]int, len(request.Methods))
+	for name, weight := range request.Methods {
+		method := strings.ToUpper(name)
+		if !slices.Contains(httpMethods, method) {
+			return fmt.Errorf("%q", name)
+		}
+		methods[method] += weight
+	}
+	request.Methods = methods
+	return nil
+}
+
+// 按权重随机选择一个下标,权重总和不大于0时返回-1
+func pickWeightedIndex(weights []int) int {
+	total := 0
+	for _, weight := range weights {
+		if weight > 0 {
+			total += weight
+		}
+	}
+	if total <= 0 {
+		return -1
+	}
+	n := rand.IntN(total)
+	for index, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		if n < weight {
+			return index
+		}
+		n -= weight
+	}
+	return -1
+}
+
+// 按权重随机选择一个请求方法,权重总和不大于0时返回空字符串
+func pickWeightedMethod(methods map[string]int) string {
+	names := make([]string, 0, len(methods))
+	weights := make([]int, 0, len(methods))
+	for method, weight := range methods {
+		names = append(names, method)
+		weights = append(weights, weight)
+	}
+	if index := pickWeightedIndex(weights); index >= 0 {
+		return names[index]
+	}
+	return ""
+}
+
+func (h *RequestHandler) setRequestHeaders(req *http.Request, headers map[string]string, auth *AuthConfig) {
 	for k, v := range h.defaultHeaders {
 		if _, exists := headers[k]; !exists {
 			req.Header.Set(k, v)
 		}
 	}
+	if auth != nil {
+		req.Header.Set("Authorization", auth.header())
+	}
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	// 没有 User-Agent 时 net/http 会添加 Go-http-client,设置为空值使其不发送
+	if _, ok := req.Header["User-Agent"]; !ok {
+		req.Header["User-Agent"] = []string{""}
+	}
 }
 
-// 读取JSON配置文件
+// 最近一次读取的配置文件内容的SHA-256,读取目录时为按文件名排序后所有配置文件内容的SHA-256
+var configHash string
+
+// -f 为该值时从标准输入读取JSON配置
+const stdinConfigFile = "-"
+
+// 读取JSON配置文件,filePath 为目录时按文件名顺序读取目录中所有 .json/.yaml/.yml 文件并合并为一个配置列表
 func ReadConfig(filePath string) ([]RequestConfig, error) {
-	//取文件名称,是否存在
+	var requestList []RequestConfig
+	var err error
+	hash := sha256.New()
+	if filePath == stdinConfigFile {
+		// 从标准输入读取,便于通过管道传入生成的配置
+		var data []byte
+		if data, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, err
+		}
+		hash.Write(data)
+		if requestList, err = parseConfig(data, ""); err != nil {
+			return nil, err
+		}
+	} else {
+		//取文件名称,是否存在
+		info, err := os.Stat(filePath)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf(tr("file_not_exist"), err)
+		}
+		isDir := err == nil && info.IsDir()
+		files := []string{filePath}
+		if isDir {
+			if files, err = configDirFiles(filePath); err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			hash.Write(data)
+			fileList, err := parseConfig(data, filepath.Ext(file))
+			if err != nil {
+				if isDir {
+					return nil, fmt.Errorf(tr("config_dir_file_invalid"), file, err)
+				}
+				return nil, err
+			}
+			// 读取目录时记录每个配置所在的文件,结果中按文件名标识
+			if isDir {
+				for index := range fileList {
+					fileList[index].sourceFile = filepath.Base(file)
+				}
+			}
+			requestList = append(requestList, fileList...)
+		}
+	}
+	configHash = hex.EncodeToString(hash.Sum(nil))
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("文件不存在: %v", err)
+	// 所有配置中 Extract 声明的变量名,这些引用不作为环境变量替换
+	extractNames := make(map[string]bool)
+	for _, request := range requestList {
+		for _, name := range request.Extract {
+			extractNames[name] = true
+		}
+	}
+	for index := range requestList {
+		keepNames := extractNames
+		if requestList[index].DataFile != "" {
+			rows, err := loadDataFile(requestList[index].DataFile)
+			if err != nil {
+				return nil, fmt.Errorf(tr("data_file_invalid"), index+1, err)
+			}
+			requestList[index].dataRows = rows
+			keepNames = rows.keepNames(extractNames)
+		}
+		if requestList[index], err = expandConfigEnv(requestList[index], keepNames); err != nil {
+			return nil, fmt.Errorf(tr("env_expand_failed"), index+1, err)
+		}
+		if requestList[index].templated, err = checkTemplates(requestList[index]); err != nil {
+			return nil, fmt.Errorf(tr("template_invalid"), index+1, err)
+		}
+	}
+
+	for index, request := range requestList {
+		// 请求体文件和文件字段在读取配置文件时加载,测试过程中不再读取磁盘
+		if request.BodyFile != "" {
+			if requestList[index].bodyFileData, err = os.ReadFile(request.BodyFile); err != nil {
+				return nil, fmt.Errorf(tr("body_file_invalid"), index+1, err)
+			}
+		}
+		if len(request.Files) > 0 {
+			requestList[index].fileData = make(map[string][]byte, len(request.Files))
+			for name, file := range request.Files {
+				if requestList[index].fileData[name], err = os.ReadFile(file); err != nil {
+					return nil, fmt.Errorf(tr("body_file_invalid"), index+1, err)
+				}
+			}
+		}
+		for key, value := range request.Response.Data {
+			operator, ok, err := parseFieldOperator(value)
+			if err != nil {
+				return nil, fmt.Errorf(tr("field_operator_invalid"), index+1, key, value, err)
+			}
+			if ok {
+				if requestList[index].Response.operators == nil {
+					requestList[index].Response.operators = make(map[string]fieldOperator)
+				}
+				requestList[index].Response.operators[key] = operator
+				continue
+			}
+			expr, ok := value.(string)
+			if !ok || !strings.HasPrefix(expr, regexFieldPrefix) {
+				continue
+			}
+			pattern, err := regexp.Compile(strings.TrimPrefix(expr, regexFieldPrefix))
+			if err != nil {
+				return nil, fmt.Errorf(tr("field_regex_invalid"), index+1, key, err)
+			}
+			if requestList[index].Response.patterns == nil {
+				requestList[index].Response.patterns = make(map[string]*regexp.Regexp)
+			}
+			requestList[index].Response.patterns[key] = pattern
+		}
+		if request.Response.Schema != nil {
+			schema, err := compileSchema(request.Response.Schema, index+1)
+			if err != nil {
+				return nil, fmt.Errorf(tr("schema_invalid"), index+1, err)
+			}
+			requestList[index].Response.schema = schema
+		}
+		switch request.ParamsMode {
+		case "", paramsAppend, paramsReplace:
+		default:
+			return nil, fmt.Errorf(tr("params_mode_invalid"), index+1, request.ParamsMode)
+		}
+		switch request.Response.Match {
+		case "", matchAll, matchAny:
+		default:
+			return nil, fmt.Errorf(tr("match_invalid"), index+1, request.Response.Match)
+		}
+		switch request.Protocol {
+		case "", "http":
+			if err := normalizeMethods(&requestList[index]); err != nil {
+				return nil, fmt.Errorf(tr("method_invalid"), index+1, err)
+			}
+		case protocolGRPC:
+			if request.Service == "" || request.Method == "" {
+				return nil, fmt.Errorf(tr("grpc_config_invalid"), index+1)
+			}
+		case protocolWebSocket:
+			if !strings.HasPrefix(request.URL, "ws://") && !strings.HasPrefix(request.URL, "wss://") {
+				return nil, fmt.Errorf(tr("ws_url_invalid"), index+1, request.URL)
+			}
+		default:
+			return nil, fmt.Errorf(tr("protocol_invalid"), index+1, request.Protocol)
+		}
+		if request.Proxy != "" {
+			proxy, err := parseProxyURL(request.Proxy)
+			if err != nil {
+				return nil, fmt.Errorf(tr("proxy_invalid"), index+1, err)
+			}
+			requestList[index].proxyURL = proxy
+		}
+		if request.Auth != nil {
+			if err := request.Auth.Validate(); err != nil {
+				return nil, fmt.Errorf(tr("auth_invalid"), index+1, err)
+			}
+		}
+		if request.Weight < 0 {
+			return nil, fmt.Errorf(tr("weight_invalid"), index+1, request.Weight)
+		}
+		if request.Timeout < 0 {
+			return nil, fmt.Errorf(tr("timeout_invalid"), index+1, time.Duration(request.Timeout))
+		}
+		if request.Response.Assert != nil {
+			if err := request.Response.Assert.Validate(); err != nil {
+				return nil, fmt.Errorf(tr("assert_invalid"), index+1, err)
+			}
+		}
 	}
 
-	data, err := os.ReadFile(filePath)
+	return requestList, nil
+}
+
+// 目录中的配置文件,按文件名排序,不包括子目录
+func configDirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
+	var files []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".json", ".yaml", ".yml":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf(tr("config_dir_empty"), dir)
+	}
+	return files, nil
+}
+
+// 解析配置文件内容,ext 为 .yaml/.yml 时按YAML解析,其他按JSON解析
+func parseConfig(data []byte, ext string) ([]RequestConfig, error) {
+	// .yaml/.yml 文件先解析为通用结构再转换为JSON,与JSON配置使用相同的字段名和数值类型
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		var value any
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
 
 	var requestList []RequestConfig
 	if err := json.Unmarshal(data, &requestList); err != nil {
 		return nil, err
 	}
-
 	return requestList, nil
 }
 
+// 环境变量未设置时是否报错,通过 -strict-env 指定,否则替换为空字符串
+var strictEnv bool
+
+// 替换请求配置中的环境变量引用,keepNames 中的变量名保留,包括 Extract 中声明的变量和数据文件的列名
+func expandConfigEnv(request RequestConfig, keepNames map[string]bool) (RequestConfig, error) {
+	var missing []string
+	expanded := expandConfig(request, func(name string) string {
+		if keepNames[name] {
+			return "${" + name + "}"
+		}
+		envValue, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return envValue
+	})
+	if strictEnv && len(missing) > 0 {
+		return request, fmt.Errorf(tr("env_not_set"), missing)
+	}
+	return expanded, nil
+}
+
+// 使用 mapping 替换请求配置中URL、请求头、请求参数、请求体、表单字段和认证信息的 ${VAR}/$VAR 引用
+// 返回替换后的副本,不修改原配置中的 map 和切片
+func expandConfig(request RequestConfig, mapping func(string) string) RequestConfig {
+	return transformConfig(request, func(s string) string {
+		return os.Expand(s, mapping)
+	})
+}
+
+// 使用 transform 转换请求配置中URL、请求头、请求参数、请求体、表单字段和认证信息中的所有字符串
+// 返回转换后的副本,不修改原配置中的 map 和切片
+func transformConfig(request RequestConfig, transform func(string) string) RequestConfig {
+	request.URL = transform(request.URL)
+	if request.Headers != nil {
+		headers := make(map[string]string, len(request.Headers))
+		for key, value := range request.Headers {
+			headers[key] = transform(value)
+		}
+		request.Headers = headers
+	}
+	if request.OrderedHeaders != nil {
+		orderedHeaders := make([][2]string, len(request.OrderedHeaders))
+		for i, header := range request.OrderedHeaders {
+			orderedHeaders[i] = [2]string{header[0], transform(header[1])}
+		}
+		request.OrderedHeaders = orderedHeaders
+	}
+	if request.Params != nil {
+		request.Params = transformValue(request.Params, transform).(map[string]interface{})
+	}
+	request.Data = transformValue(request.Data, transform)
+	if request.Auth != nil {
+		request.Auth = &AuthConfig{
+			Type:  request.Auth.Type,
+			User:  transform(request.Auth.User),
+			Pass:  transform(request.Auth.Pass),
+			Token: transform(request.Auth.Token),
+		}
+	}
+	if request.Form != nil {
+		form := make(map[string]string, len(request.Form))
+		for key, value := range request.Form {
+			form[key] = transform(value)
+		}
+		request.Form = form
+	}
+	return request
+}
+
+// 递归转换值中的所有字符串,返回新的 map 和切片
+func transformValue(value any, transform func(string) string) any {
+	switch v := value.(type) {
+	case string:
+		return transform(v)
+	case map[string]interface{}:
+		transformed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			transformed[key] = transformValue(item, transform)
+		}
+		return transformed
+	case []interface{}:
+		transformed := make([]interface{}, len(v))
+		for i, item := range v {
+			transformed[i] = transformValue(item, transform)
+		}
+		return transformed
+	}
+	return value
+}
+
 func writeFile(filePath string, data []byte) error {
 	err := os.WriteFile(filePath, data, 0644)
 	if err != nil {
@@ -147,6 +1361,107 @@ func writeFile(filePath string, data []byte) error {
 	return nil
 }
 
+// Response.Data 中使用正则表达式匹配的期望值前缀,如 regex:^[0-9a-f-]{36}$
+const regexFieldPrefix = "regex:"
+
+// 数值比较的误差范围
+const fieldEpsilon = 1e-9
+
+// 判断响应字段是否等于期望值
+// 数值按 float64 在误差范围内比较,字符串和布尔值要求类型一致,对象和数组按内容比较
+func fieldEqual(expected any, actual gjson.Result) bool {
+	switch v := expected.(type) {
+	case nil:
+		return !actual.Exists() || actual.Type == gjson.Null
+	case float64:
+		return actual.Type == gjson.Number && math.Abs(actual.Float()-v) <= fieldEpsilon
+	case int:
+		return actual.Type == gjson.Number && math.Abs(actual.Float()-float64(v)) <= fieldEpsilon
+	case string:
+		return actual.Type == gjson.String && actual.String() == v
+	case bool:
+		return (actual.Type == gjson.True || actual.Type == gjson.False) && actual.Bool() == v
+	}
+	return reflect.DeepEqual(actual.Value(), expected)
+}
+
+// 数值范围断言,Response.Data 中值为 {"min": x, "max": y} 的字段按范围校验,包含边界
+type fieldRange struct {
+	Min *float64
+	Max *float64
+}
+
+// 判断期望值是否为数值范围配置: 只包含 min、max 且值都是数字的对象
+func parseFieldRange(expected any) (fieldRange, bool) {
+	spec, ok := expected.(map[string]interface{})
+	if !ok || len(spec) == 0 {
+		return fieldRange{}, false
+	}
+	var r fieldRange
+	for key, value := range spec {
+		number, ok := value.(float64)
+		if !ok {
+			return fieldRange{}, false
+		}
+		switch key {
+		case "min":
+			r.Min = &number
+		case "max":
+			r.Max = &number
+		default:
+			return fieldRange{}, false
+		}
+	}
+	return r, true
+}
+
+// 实际值是否为数字且在范围内
+func (r fieldRange) Contains(value gjson.Result) bool {
+	if value.Type != gjson.Number {
+		return false
+	}
+	number := value.Float()
+	if r.Min != nil && number < *r.Min {
+		return false
+	}
+	if r.Max != nil && number > *r.Max {
+		return false
+	}
+	return true
+}
+
+// 范围的可读表示,用于错误信息统计
+func (r fieldRange) String() string {
+	switch {
+	case r.Min != nil && r.Max != nil:
+		return fmt.Sprintf("[%v, %v]", *r.Min, *r.Max)
+	case r.Min != nil:
+		return fmt.Sprintf(">= %v", *r.Min)
+	}
+	return fmt.Sprintf("<= %v", *r.Max)
+}
+
+// 获取gjson结果的类型名称,与 Response.Types 中的类型名称对应
+func jsonTypeName(value gjson.Result) string {
+	if !value.Exists() {
+		return "missing"
+	}
+	switch value.Type {
+	case gjson.String:
+		return "string"
+	case gjson.Number:
+		return "number"
+	case gjson.True, gjson.False:
+		return "bool"
+	case gjson.Null:
+		return "null"
+	}
+	if value.IsArray() {
+		return "array"
+	}
+	return "object"
+}
+
 // 毫秒大于1000时转秒，带单位ms或者s
 func MsToSeconds(ms int64) string {
 	if ms > 1000 {
@@ -166,6 +1481,163 @@ func average(durations []int64) int64 {
 	return total / int64(len(durations))
 }
 
+// 按 interval 毫秒分桶统计耗时分布,超出最大耗时所在桶的样本计入最后一个桶
+func latencyDistribution(durations []int64, maxMs, interval int64) []int {
+	maxInterval := maxMs/interval + 1
+	distribution := make([]int, maxInterval)
+	for _, d := range durations {
+		index := d / interval
+		if index >= maxInterval {
+			index = maxInterval - 1
+		}
+		distribution[index]++
+	}
+	return distribution
+}
+
+// 获取JSON的结构,返回排序后的所有key路径,数组元素的key合并到 "数组路径.#" 下
+func jsonShape(body string) []string {
+	paths := make(map[string]struct{})
+	collectShape(gjson.Parse(body), "", paths)
+	shape := make([]string, 0, len(paths))
+	for path := range paths {
+		shape = append(shape, path)
+	}
+	sort.Strings(shape)
+	return shape
+}
+
+func collectShape(value gjson.Result, prefix string, paths map[string]struct{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	if value.IsObject() {
+		value.ForEach(func(key, child gjson.Result) bool {
+			path := join(key.String())
+			paths[path] = struct{}{}
+			collectShape(child, path, paths)
+			return true
+		})
+	} else if value.IsArray() {
+		path := join("#")
+		value.ForEach(func(_, child gjson.Result) bool {
+			paths[path] = struct{}{}
+			collectShape(child, path, paths)
+			return true
+		})
+	}
+}
+
+// 对比两个响应结构,返回实际结构中新增和缺失的key路径
+func diffShape(expected, actual []string) (added, removed []string) {
+	expectedSet := shapeSet(expected)
+	actualSet := shapeSet(actual)
+	for _, path := range actual {
+		if _, ok := expectedSet[path]; !ok {
+			added = append(added, path)
+		}
+	}
+	for _, path := range expected {
+		if _, ok := actualSet[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	return added, removed
+}
+
+// 将结构的key路径转换为集合,便于逐个查找
+func shapeSet(paths []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		set[path] = struct{}{}
+	}
+	return set
+}
+
+// 按最近秩法计算耗时百分位数,p 取值 0-100,不修改原切片,空切片返回0
+func percentile(durations []int64, p float64) int64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return sorted[rank-1]
+}
+
+// 根据请求数和总耗时(毫秒)计算QPS,耗时为0时返回0
+func qps(count, totalMs int64) float64 {
+	if totalMs <= 0 {
+		return 0
+	}
+	return float64(count) / float64(totalMs) * 1000
+}
+
+// 计算每秒传输的MB数(1MB=1024*1024字节),总耗时为0时返回0
+func throughputMBps(bytes, totalMs int64) float64 {
+	if totalMs <= 0 {
+		return 0
+	}
+	return float64(bytes) / (1024 * 1024) / float64(totalMs) * 1000
+}
+
+// 计算百分比,总数为0时返回0
+func ratioPercent(count, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(count) / float64(total) * 100
+}
+
+// 生成随机的 UUID v4 字符串
+func newUUID() string {
+	var b [16]byte
+	crand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// 错误状态码的请求总数
+func sumErrorCodes(errorCodes map[int]int) int64 {
+	var total int64
+	for _, count := range errorCodes {
+		total += int64(count)
+	}
+	return total
+}
+
+// 按状态码类别统计状态码错误次数,返回4xx和5xx的次数,其他状态码(如gRPC状态码)不计入
+func sumErrorCodeClasses(errorCodes map[int]int) (clientErrors, serverErrors int64) {
+	for code, count := range errorCodes {
+		switch code / 100 {
+		case 4:
+			clientErrors += int64(count)
+		case 5:
+			serverErrors += int64(count)
+		}
+	}
+	return clientErrors, serverErrors
+}
+
+// 耗时为0ms的样本占比
+func zeroRatio(durations []int64) float64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	zeros := 0
+	for _, d := range durations {
+		if d == 0 {
+			zeros++
+		}
+	}
+	return float64(zeros) / float64(len(durations))
+}
+
 func maxDuration(durations []int64) int64 {
 	max := int64(0)
 	for _, d := range durations {
@@ -175,3 +1647,30 @@ func maxDuration(durations []int64) int64 {
 	}
 	return max
 }
+
+// 计算耗时的总体标准差,使用 float64 按 Welford 算法累加,避免平方和在大量请求时溢出
+func stdDevDuration(durations []int64) int64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	var mean, m2 float64
+	for i, d := range durations {
+		delta := float64(d) - mean
+		mean += delta / float64(i+1)
+		m2 += delta * (float64(d) - mean)
+	}
+	return int64(math.Round(math.Sqrt(m2 / float64(len(durations)))))
+}
+
+func minDuration(durations []int64) int64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	min := durations[0]
+	for _, d := range durations {
+		if d < min {
+			min = d
+		}
+	}
+	return min
+}
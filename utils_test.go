@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// 并发读取请求体时每个读取器都必须得到完整且未被其他协程干扰的内容
+func TestCreateRequestBodyConcurrent(t *testing.T) {
+	const workers = 64
+	const iterations = 50
+	data := map[string]any{
+		"name":  strings.Repeat("张三", 512),
+		"items": []any{1.0, "two", true, nil, map[string]any{"nested": "value"}},
+	}
+	expected, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := NewRequestHandler(time.Second)
+
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			// 所有协程共用同一个请求体工厂
+			newBody, err := handler.createRequestBody(data, compress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var wg sync.WaitGroup
+			for worker := range workers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range iterations {
+						body := newBody
+						// 一半的请求各自创建请求体工厂
+						if i%2 == 1 {
+							var err error
+							if body, err = handler.createRequestBody(data, compress); err != nil {
+								t.Errorf("worker %d: %v", worker, err)
+								return
+							}
+						}
+						got, err := readRequestBody(body(), compress)
+						if err != nil {
+							t.Errorf("worker %d: %v", worker, err)
+							return
+						}
+						if !bytes.Equal(got, expected) {
+							t.Errorf("worker %d iteration %d: body corrupted, got %d bytes, want %d", worker, i, len(got), len(expected))
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// 并发构建同一配置的请求时每个请求的请求体都是独立完整的
+func TestBuildRequestBodyConcurrent(t *testing.T) {
+	const workers = 64
+	const iterations = 50
+	body := strings.Repeat("0123456789abcdef", 1024)
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			config := RequestConfig{URL: "http://127.0.0.1/upload", Method: "POST", Data: body, CompressRequest: compress}
+			handler := NewRequestHandler(time.Second)
+			var wg sync.WaitGroup
+			for worker := range workers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range iterations {
+						req, _, err := handler.BuildRequest(context.Background(), config, nil)
+						if err != nil {
+							t.Errorf("worker %d: %v", worker, err)
+							return
+						}
+						if got := req.Header.Get("Content-Encoding") == "gzip"; got != compress {
+							t.Errorf("worker %d: Content-Encoding gzip = %v, want %v", worker, got, compress)
+							return
+						}
+						got, err := readRequestBody(req.Body, compress)
+						if err != nil {
+							t.Errorf("worker %d: %v", worker, err)
+							return
+						}
+						if string(got) != body {
+							t.Errorf("worker %d iteration %d: body corrupted, got %d bytes, want %d", worker, i, len(got), len(body))
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// 读取请求体,compress 为 true 时先解压
+func readRequestBody(body io.Reader, compress bool) ([]byte, error) {
+	if compress {
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		body = reader
+	}
+	return io.ReadAll(body)
+}
+
+// 字段相等判断: 数值按值比较(不区分整数和小数写法),字符串、布尔值和 null 要求类型一致
+func TestFieldEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected any
+		json     string
+		path     string
+		want     bool
+	}{
+		{"float equals integer literal", 1.0, `{"v":1}`, "v", true},
+		{"float equals decimal literal", 1.0, `{"v":1.0}`, "v", true},
+		{"float equals exponent literal", 100.0, `{"v":1e2}`, "v", true},
+		{"float differs", 1.0, `{"v":1.5}`, "v", false},
+		{"float within epsilon", 0.1 + 0.2, `{"v":0.3}`, "v", true},
+		{"int equals integer literal", 1, `{"v":1}`, "v", true},
+		{"int equals decimal literal", 1, `{"v":1.0}`, "v", true},
+		{"int differs", 2, `{"v":1}`, "v", false},
+		{"large number", 1e15, `{"v":1000000000000000}`, "v", true},
+		{"number vs numeric string", 200.0, `{"v":"200"}`, "v", false},
+		{"int vs numeric string", 200, `{"v":"200"}`, "v", false},
+		{"string vs number", "200", `{"v":200}`, "v", false},
+		{"string equals", "ok", `{"v":"ok"}`, "v", true},
+		{"string differs", "ok", `{"v":"OK"}`, "v", false},
+		{"bool equals", true, `{"v":true}`, "v", true},
+		{"bool differs", false, `{"v":true}`, "v", false},
+		{"bool vs string", true, `{"v":"true"}`, "v", false},
+		{"bool vs number", true, `{"v":1}`, "v", false},
+		{"nil matches null", nil, `{"v":null}`, "v", true},
+		{"nil matches missing", nil, `{}`, "v", true},
+		{"nil vs zero", nil, `{"v":0}`, "v", false},
+		{"number vs missing", 0.0, `{}`, "v", false},
+		{"array equals", []any{1.0, "a", true}, `{"v":[1,"a",true]}`, "v", true},
+		{"array with decimal literal", []any{1.0}, `{"v":[1.0]}`, "v", true},
+		{"array order differs", []any{"a", 1.0}, `{"v":[1,"a"]}`, "v", false},
+		{"object equals", map[string]any{"a": 1.0, "b": "x"}, `{"v":{"b":"x","a":1}}`, "v", true},
+		{"object differs", map[string]any{"a": 1.0}, `{"v":{"a":2}}`, "v", false},
+		{"object vs array", map[string]any{}, `{"v":[]}`, "v", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldEqual(tt.expected, gjson.Get(tt.json, tt.path)); got != tt.want {
+				t.Errorf("fieldEqual(%#v, %s) = %v, want %v", tt.expected, tt.json, got, tt.want)
+			}
+		})
+	}
+}
+
+// 关闭处理器时关闭缓存的gRPC连接和空闲的WebSocket连接
+func TestRequestHandlerClose(t *testing.T) {
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		io.Copy(io.Discard, ws)
+	}))
+	defer server.Close()
+	handler := NewRequestHandler(time.Second)
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	wsConn, err := dialWebSocket(context.Background(), RequestConfig{URL: wsURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.ws.put(wsURL, wsConn)
+	conn, err := grpc.NewClient("127.0.0.1:1", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.grpc.conns = map[string]*grpc.ClientConn{"grpc://127.0.0.1:1": conn}
+	handler.Close()
+	if state := conn.GetState(); state != connectivity.Shutdown {
+		t.Errorf("grpc conn state = %v, want %v", state, connectivity.Shutdown)
+	}
+	if handler.grpc.conns != nil {
+		t.Errorf("grpc conns not cleared after Close")
+	}
+	if _, err := wsConn.Write([]byte("ping")); err == nil {
+		t.Errorf("websocket conn still writable after Close")
+	}
+	if handler.ws.get(wsURL) != nil {
+		t.Errorf("idle websocket conns not cleared after Close")
+	}
+}
+
+// 关闭处理器时关闭独立 Transport 中的空闲HTTP长连接
+func TestRequestHandlerCloseIdleHTTP(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	handler := NewRequestHandler(time.Second)
+	handler.client.Transport = &http.Transport{}
+	resp, _, err := handler.NewRequest(context.Background(), RequestConfig{URL: server.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	handler.Close()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Error("idle keep-alive connection not closed after Close")
+	}
+}
+
+// 按权重随机选择请求方法: 权重不大于0的方法不会被选中,选中比例接近权重比例
+func TestPickWeightedMethod(t *testing.T) {
+	if got := pickWeightedMethod(nil); got != "" {
+		t.Errorf("pickWeightedMethod(nil) = %q, want empty", got)
+	}
+	if got := pickWeightedMethod(map[string]int{"GET": 0, "POST": -1}); got != "" {
+		t.Errorf("pickWeightedMethod(no positive weight) = %q, want empty", got)
+	}
+	const rounds = 20000
+	counts := make(map[string]int)
+	for range rounds {
+		counts[pickWeightedMethod(map[string]int{"GET": 3, "POST": 1, "PUT": 0})]++
+	}
+	if counts["PUT"] != 0 || counts[""] != 0 {
+		t.Errorf("unexpected picks: %v", counts)
+	}
+	if ratio := float64(counts["GET"]) / rounds; ratio < 0.72 || ratio > 0.78 {
+		t.Errorf("GET picked %.3f of the time, want about 0.75", ratio)
+	}
+}
+
+// 期望状态码的解析: 支持数字、字符串和数组,状态码范围只支持 1xx-5xx 的写法
+func TestExpectedStatusUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    expectedStatus
+		wantErr bool
+	}{
+		{"number", `200`, expectedStatus{"200"}, false},
+		{"string", `"204"`, expectedStatus{"204"}, false},
+		{"range", `"2xx"`, expectedStatus{"2xx"}, false},
+		{"range upper case", `"3XX"`, expectedStatus{"3xx"}, false},
+		{"number list", `[200, 201, 204]`, expectedStatus{"200", "201", "204"}, false},
+		{"mixed list", `["2xx", 304]`, expectedStatus{"2xx", "304"}, false},
+		{"null", `null`, nil, false},
+		{"empty list", `[]`, nil, false},
+		{"out of range", `600`, nil, true},
+		{"below range", `99`, nil, true},
+		{"decimal", `200.5`, nil, true},
+		{"partial range", `"2x"`, nil, true},
+		{"dash range", `"200-299"`, nil, true},
+		{"letters", `"abc"`, nil, true},
+		{"bool", `true`, nil, true},
+		{"invalid item in list", `[200, "6xx"]`, nil, true},
+		{"object", `{"code":200}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got expectedStatus
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("Unmarshal(%s) = %q, want %q", tt.json, got, tt.want)
+			}
+		})
+	}
+}
+
+// 期望状态码的匹配: 为空时只匹配200,范围按百位匹配
+func TestExpectedStatusMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		status expectedStatus
+		code   int
+		want   bool
+	}{
+		{"default matches 200", nil, 200, true},
+		{"default rejects 201", nil, 201, false},
+		{"exact match", expectedStatus{"204"}, 204, true},
+		{"exact mismatch", expectedStatus{"204"}, 200, false},
+		{"range lower bound", expectedStatus{"2xx"}, 200, true},
+		{"range upper bound", expectedStatus{"2xx"}, 299, true},
+		{"range below", expectedStatus{"2xx"}, 199, false},
+		{"range above", expectedStatus{"2xx"}, 300, false},
+		{"mixed list range", expectedStatus{"2xx", "304"}, 201, true},
+		{"mixed list exact", expectedStatus{"2xx", "304"}, 304, true},
+		{"mixed list miss", expectedStatus{"2xx", "304"}, 302, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.matches(tt.code); got != tt.want {
+				t.Errorf("%q.matches(%d) = %v, want %v", tt.status, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+// 按 Content-Encoding 解压响应体: 支持gzip、zlib格式和原始格式的deflate,空响应体不报错,损坏的数据读取时报错
+func TestDecodeResponseBody(t *testing.T) {
+	const text = `{"message":"你好,世界"}`
+	gzipped, _ := gzipBytes([]byte(text))
+	var zlibbed, raw bytes.Buffer
+	zw := zlib.NewWriter(&zlibbed)
+	zw.Write([]byte(text))
+	zw.Close()
+	fw, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	fw.Write([]byte(text))
+	fw.Close()
+
+	tests := []struct {
+		name         string
+		encoding     string
+		uncompressed bool
+		body         []byte
+		want         string
+		wantDecoded  bool
+		wantErr      bool
+	}{
+		{"gzip", "gzip", false, gzipped, text, true, false},
+		{"gzip mixed case", " GZip ", false, gzipped, text, true, false},
+		{"deflate zlib", "deflate", false, zlibbed.Bytes(), text, true, false},
+		{"deflate raw", "deflate", false, raw.Bytes(), text, true, false},
+		{"identity", "", false, []byte(text), text, false, false},
+		{"unsupported encoding", "br", false, []byte("opaque"), "opaque", false, false},
+		{"already decoded by transport", "gzip", true, []byte(text), text, false, false},
+		{"empty gzip body", "gzip", false, nil, "", true, false},
+		{"corrupt gzip", "gzip", false, []byte("not gzip data"), "", true, true},
+		{"corrupt deflate", "deflate", false, []byte{0xff, 0xff, 0xff}, "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header:        http.Header{"Content-Length": {fmt.Sprint(len(tt.body))}},
+				Body:          io.NopCloser(bytes.NewReader(tt.body)),
+				ContentLength: int64(len(tt.body)),
+				Uncompressed:  tt.uncompressed,
+			}
+			if tt.encoding != "" {
+				resp.Header.Set("Content-Encoding", tt.encoding)
+			}
+			decodeResponseBody(resp)
+			got, err := io.ReadAll(resp.Body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("read error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			if tt.wantDecoded {
+				if resp.Header.Get("Content-Encoding") != "" || resp.Header.Get("Content-Length") != "" || resp.ContentLength != -1 || !resp.Uncompressed {
+					t.Errorf("decoded response keeps encoding headers: %v, ContentLength %d, Uncompressed %v", resp.Header, resp.ContentLength, resp.Uncompressed)
+				}
+			} else if resp.ContentLength != int64(len(tt.body)) {
+				t.Errorf("ContentLength = %d, want unchanged %d", resp.ContentLength, len(tt.body))
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebSocket请求使用的协议名称
+const protocolWebSocket = "ws"
+
+// 每个请求处理器缓存的空闲WebSocket连接,key为连接地址
+// 每个工作协程取出一个连接发送消息,收到回复后放回,因此连接数不超过并发数,连接出错时关闭并在下次请求时重新建立
+type wsClients struct {
+	mu   sync.Mutex
+	idle map[string][]*websocket.Conn
+}
+
+// 取出一个空闲连接,没有时返回 nil
+func (c *wsClients) get(address string) *websocket.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	conns := c.idle[address]
+	if len(conns) == 0 {
+		return nil
+	}
+	conn := conns[len(conns)-1]
+	c.idle[address] = conns[:len(conns)-1]
+	return conn
+}
+
+// 放回空闲连接
+func (c *wsClients) put(address string, conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.idle == nil {
+		c.idle = make(map[string][]*websocket.Conn)
+	}
+	c.idle[address] = append(c.idle[address], conn)
+}
+
+// 关闭所有空闲连接
+func (c *wsClients) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, conns := range c.idle {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+	c.idle = nil
+}
+
+// 建立WebSocket连接,请求头和认证信息在握手请求中发送,wss:// 使用 -insecure、-cert、-key 生成的TLS配置
+func dialWebSocket(ctx context.Context, config RequestConfig) (*websocket.Conn, error) {
+	location, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	origin := &url.URL{Scheme: "http", Host: location.Host}
+	if location.Scheme == "wss" {
+		origin.Scheme = "https"
+	}
+	wsConfig, err := websocket.NewConfig(config.URL, origin.String())
+	if err != nil {
+		return nil, err
+	}
+	wsConfig.TlsConfig = tlsConfig
+	if len(bindAddrs) > 0 {
+		wsConfig.Dialer = bindDialer(net.Dialer{}, net.JoinHostPort(location.Hostname(), location.Port()))
+	}
+	if config.Auth != nil {
+		wsConfig.Header.Set("Authorization", config.Auth.header())
+	}
+	for key, value := range config.Headers {
+		wsConfig.Header.Set(key, value)
+	}
+	return wsConfig.DialContext(ctx)
+}
+
+// 在WebSocket连接上发送一条消息并等待回复,将回复转换为HTTP响应以便使用相同的校验和统计逻辑
+// 收到回复时状态码为200,响应体为回复内容;需要新建连接时握手耗时记录在 timing.Handshake 中,不计入消息往返耗时
+func (h *RequestHandler) newWebSocketRequest(ctx context.Context, config RequestConfig, timing *RequestTiming) (*http.Response, *http.Client, error) {
+	prepareStart := time.Now()
+	var message []byte
+	if config.Data != nil {
+		var err error
+		if message, err = encodeRequestBody(config.Data); err != nil {
+			return nil, nil, err
+		}
+	}
+	if timing != nil {
+		timing.Prepare = time.Since(prepareStart)
+	}
+
+	conn := h.ws.get(config.URL)
+	if conn == nil {
+		handshakeStart := time.Now()
+		dialCtx, cancel := context.WithTimeout(ctx, h.client.Timeout)
+		var err error
+		conn, err = dialWebSocket(dialCtx, config)
+		cancel()
+		if err != nil {
+			if timing != nil {
+				timing.setConnectErr(err)
+			}
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			return nil, nil, err
+		}
+		if timing != nil {
+			timing.Handshake = time.Since(handshakeStart)
+		}
+	}
+
+	// 超时时读写返回 net.Error 超时错误,请求被取消时通过过期的截止时间中断读写
+	conn.SetDeadline(time.Now().Add(h.client.Timeout))
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	var err error
+	var reply []byte
+	// 消息为有效的UTF-8时作为文本帧发送,否则作为二进制帧发送
+	if utf8.Valid(message) {
+		err = websocket.Message.Send(conn, string(message))
+	} else {
+		err = websocket.Message.Send(conn, message)
+	}
+	if err == nil {
+		err = websocket.Message.Receive(conn, &reply)
+	}
+	stop()
+	if timing != nil {
+		timing.FirstByte = time.Now()
+	}
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf(tr("ws_closed"), err)
+		}
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	h.ws.put(config.URL, conn)
+
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(bytes.NewReader(reply)),
+		ContentLength: int64(len(reply)),
+		Request:       &http.Request{Method: http.MethodGet, URL: conn.Config().Location, Header: conn.Config().Header},
+	}, nil, nil
+}